@@ -3,6 +3,7 @@ module github.com/mitchellh/terraform-provider-multispace
 go 1.16
 
 require (
+	github.com/hashicorp/go-hclog v0.15.0
 	github.com/hashicorp/go-tfe v0.19.0
 	github.com/hashicorp/hcl v1.0.0
 	github.com/hashicorp/terraform-plugin-docs v0.5.1
@@ -0,0 +1,67 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/hashicorp/go-tfe"
+)
+
+// apiClient makes requests to Terraform Enterprise API endpoints that
+// go-tfe doesn't support yet. It uses the same address, token, and HTTP
+// client as the tfe.Client it was created for.
+type apiClient struct {
+	address   *url.URL
+	token     string
+	userAgent string
+	http      *http.Client
+}
+
+// apiPagination is the pagination metadata of a list response.
+type apiPagination struct {
+	CurrentPage int `json:"current-page"`
+	NextPage    int `json:"next-page"`
+	TotalPages  int `json:"total-pages"`
+}
+
+// get requests the API path, such as "runs/run-123/comments", and decodes
+// the JSON response into v.
+func (c *apiClient) get(ctx context.Context, path string, query url.Values, v interface{}) error {
+	u, err := c.address.Parse(strings.TrimPrefix(path, "/"))
+	if err != nil {
+		return err
+	}
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.api+json")
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return tfe.ErrResourceNotFound
+	case http.StatusUnauthorized:
+		return tfe.ErrUnauthorized
+	default:
+		return fmt.Errorf("unexpected status code %d from %s", resp.StatusCode, u.Path)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
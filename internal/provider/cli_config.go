@@ -2,7 +2,6 @@ package provider
 
 import (
 	"io/ioutil"
-	"log"
 	"os"
 
 	"github.com/hashicorp/hcl"
@@ -40,7 +39,7 @@ func cliConfig() *Config {
 	// login. Location isn't configurable.
 	credentialsFilePath, err := credentialsFile()
 	if err != nil {
-		log.Printf("[ERROR] Error detecting default credentials file path: %s", err)
+		logger.Named("config").Error("error detecting default credentials file path", "error", err)
 	} else {
 		credentialsConfig = readCliConfigFile(credentialsFilePath)
 	}
@@ -76,7 +75,7 @@ func locateConfigFile() string {
 	}
 	filePath, err := configFile()
 	if err != nil {
-		log.Printf("[ERROR] Error detecting default CLI config file path: %s", err)
+		logger.Named("config").Error("error detecting default CLI config file path", "error", err)
 		return ""
 	}
 
@@ -84,25 +83,26 @@ func locateConfigFile() string {
 }
 
 func readCliConfigFile(configFilePath string) *Config {
+	logger := logger.Named("config").With("path", configFilePath)
 	config := &Config{}
 
 	// Read the CLI config file content.
 	content, err := ioutil.ReadFile(configFilePath)
 	if err != nil {
-		log.Printf("[ERROR] Error reading CLI config or credentials file %s: %v", configFilePath, err)
+		logger.Error("error reading CLI config or credentials file", "error", err)
 		return config
 	}
 
 	// Parse the CLI config file content.
 	obj, err := hcl.Parse(string(content))
 	if err != nil {
-		log.Printf("[ERROR] Error parsing CLI config or credentials file %s: %v", configFilePath, err)
+		logger.Error("error parsing CLI config or credentials file", "error", err)
 		return config
 	}
 
 	// Decode the CLI config file content.
 	if err := hcl.DecodeObject(&config, obj); err != nil {
-		log.Printf("[ERROR] Error decoding CLI config or credentials file %s: %v", configFilePath, err)
+		logger.Error("error decoding CLI config or credentials file", "error", err)
 	}
 
 	return config
@@ -6,7 +6,6 @@ import (
 	"crypto/tls"
 	"errors"
 	"fmt"
-	"log"
 	"net/http"
 	"net/url"
 	"os"
@@ -29,7 +28,10 @@ var (
 	errMissingAuthToken = errors.New("Required token could not be found. Please set the token using an input variable in the provider configuration block or by using the TFE_TOKEN environment variable.")
 )
 
-func getClient(version string, tfeHost, token string, insecure bool) (*tfe.Client, error) {
+// getMeta creates the client for the given settings and returns it along
+// with the other settings the provider needs that the client doesn't
+// expose.
+func getMeta(version string, tfeHost, token string, insecure bool) (*providerMeta, error) {
 	h := tfeHost
 	if tfeHost == "" {
 		if os.Getenv("TFE_HOSTNAME") != "" {
@@ -39,7 +41,8 @@ func getClient(version string, tfeHost, token string, insecure bool) (*tfe.Clien
 		}
 	}
 
-	log.Printf("[DEBUG] Configuring client for host %q", h)
+	logger := logger.Named("client").With("host", h)
+	logger.Debug("configuring client")
 
 	// Parse the hostname for comparison,
 	hostname, err := svchost.ForComparison(h)
@@ -71,7 +74,7 @@ func getClient(version string, tfeHost, token string, insecure bool) (*tfe.Clien
 		}
 	}
 	if insecure {
-		log.Printf("[DEBUG] Warning: Client configured to skip certificate verifications")
+		logger.Warn("client configured to skip certificate verifications")
 	}
 	transport.TLSClientConfig.InsecureSkipVerify = insecure
 
@@ -131,13 +134,13 @@ func getClient(version string, tfeHost, token string, insecure bool) (*tfe.Clien
 	// configured credential helper.
 	if token == "" {
 		if os.Getenv("TFE_TOKEN") != "" {
-			log.Printf("[DEBUG] TFE_TOKEN used for token value")
+			logger.Debug("TFE_TOKEN used for token value")
 			token = os.Getenv("TFE_TOKEN")
 		} else {
-			log.Printf("[DEBUG] Attempting to fetch token from Terraform CLI configuration for hostname %s...", hostname)
+			logger.Debug("attempting to fetch token from Terraform CLI configuration")
 			creds, err := services.CredentialsForHost(hostname)
 			if err != nil {
-				log.Printf("[DEBUG] Failed to get credentials for %s: %s (ignoring)", hostname, err)
+				logger.Debug("failed to get credentials (ignoring)", "error", err)
 			}
 			if creds != nil {
 				token = creds.Token()
@@ -167,7 +170,24 @@ func getClient(version string, tfeHost, token string, insecure bool) (*tfe.Clien
 	}
 
 	client.RetryServerErrors(true)
-	return client, nil
+
+	return &providerMeta{
+		client: client,
+		webAddress: (&url.URL{
+			Scheme: address.Scheme,
+			Host:   address.Host,
+		}).String(),
+		api: &apiClient{
+			address: &url.URL{
+				Scheme: address.Scheme,
+				Host:   address.Host,
+				Path:   tfe.DefaultBasePath,
+			},
+			token:     token,
+			userAgent: providerUaString,
+			http:      httpClient,
+		},
+	}, nil
 }
 
 func credentialsSource(config *Config) auth.CredentialsSource {
@@ -0,0 +1,360 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	tfe "github.com/hashicorp/go-tfe"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceCostEstimate() *schema.Resource {
+	s := map[string]*schema.Schema{
+		"organization": {
+			Description: costEstimateDescriptions["organization"],
+			Type:        schema.TypeString,
+			Optional:    true,
+		},
+
+		"run_ids": {
+			Description: costEstimateDescriptions["run_ids"],
+			Type:        schema.TypeList,
+			Optional:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+		},
+
+		"complete": {
+			Description: costEstimateDescriptions["complete"],
+			Type:        schema.TypeBool,
+			Computed:    true,
+		},
+
+		"prior_monthly_cost": {
+			Description: costEstimateDescriptions["prior_monthly_cost"],
+			Type:        schema.TypeFloat,
+			Computed:    true,
+		},
+
+		"proposed_monthly_cost": {
+			Description: costEstimateDescriptions["proposed_monthly_cost"],
+			Type:        schema.TypeFloat,
+			Computed:    true,
+		},
+
+		"delta_monthly_cost": {
+			Description: costEstimateDescriptions["delta_monthly_cost"],
+			Type:        schema.TypeFloat,
+			Computed:    true,
+		},
+
+		"estimates": {
+			Description: costEstimateDescriptions["estimates"],
+			Type:        schema.TypeList,
+			Computed:    true,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"workspace": {
+						Description: costEstimateDescriptions["workspace"],
+						Type:        schema.TypeString,
+						Computed:    true,
+					},
+
+					"run_id": {
+						Description: costEstimateDescriptions["run_id"],
+						Type:        schema.TypeString,
+						Computed:    true,
+					},
+
+					"status": {
+						Description: costEstimateDescriptions["status"],
+						Type:        schema.TypeString,
+						Computed:    true,
+					},
+
+					"prior_monthly_cost": {
+						Description: costEstimateDescriptions["estimates.prior_monthly_cost"],
+						Type:        schema.TypeFloat,
+						Computed:    true,
+					},
+
+					"proposed_monthly_cost": {
+						Description: costEstimateDescriptions["estimates.proposed_monthly_cost"],
+						Type:        schema.TypeFloat,
+						Computed:    true,
+					},
+
+					"delta_monthly_cost": {
+						Description: costEstimateDescriptions["estimates.delta_monthly_cost"],
+						Type:        schema.TypeFloat,
+						Computed:    true,
+					},
+
+					"matched_resources_count": {
+						Description: costEstimateDescriptions["matched_resources_count"],
+						Type:        schema.TypeInt,
+						Computed:    true,
+					},
+
+					"unmatched_resources_count": {
+						Description: costEstimateDescriptions["unmatched_resources_count"],
+						Type:        schema.TypeInt,
+						Computed:    true,
+					},
+				},
+			},
+		},
+	}
+	addWorkspaceSelectorSchema(s)
+
+	// The workspace selectors are optional here since runs can be given
+	// directly instead.
+	selectorKeys := append([]string{"run_ids"}, workspaceSelectorKeys...)
+	for _, k := range selectorKeys {
+		s[k].AtLeastOneOf = selectorKeys
+	}
+	for _, k := range workspaceSelectorKeys {
+		s[k].RequiredWith = []string{"organization"}
+	}
+
+	return &schema.Resource{
+		Description: "Cost estimates of many workspaces or runs",
+
+		ReadContext: dataSourceCostEstimateRead,
+
+		Schema: s,
+	}
+}
+
+func dataSourceCostEstimateRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*providerMeta).client
+	org := d.Get("organization").(string)
+
+	var estimates []*workspaceCostEstimate
+	for _, raw := range d.Get("run_ids").([]interface{}) {
+		id := raw.(string)
+		r, err := client.Runs.Read(ctx, id)
+		if err != nil {
+			return diag.Errorf("Failed to retrieve run %q: %s", id, err)
+		}
+		if r.Workspace == nil {
+			return diag.Errorf("Run %q has no workspace", id)
+		}
+		ws, err := client.Workspaces.ReadByID(ctx, r.Workspace.ID)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+
+		e := &workspaceCostEstimate{Workspace: ws.Name, RunID: r.ID}
+		if r.CostEstimate != nil {
+			if e.CostEstimate, err = client.CostEstimates.Read(ctx, r.CostEstimate.ID); err != nil {
+				return diag.Errorf("Failed to retrieve cost estimate of run %q: %s", id, err)
+			}
+		}
+		estimates = append(estimates, e)
+	}
+
+	// Only read workspaces if a selector is set, since the organization
+	// is optional when only run IDs are given.
+	selected := false
+	for _, k := range workspaceSelectorKeys {
+		if _, ok := d.GetOk(k); ok {
+			selected = true
+		}
+	}
+	if selected {
+		workspaces, err := selectWorkspaces(ctx, client, org, d)
+		if err != nil {
+			return diag.Errorf("Failed to retrieve workspaces: %s", err)
+		}
+
+		for _, ws := range workspaces {
+			e, err := latestCostEstimate(ctx, client, ws)
+			if err != nil {
+				return diag.Errorf(
+					"Failed to retrieve cost estimate of workspace %q: %s", ws.Name, err)
+			}
+			estimates = append(estimates, e)
+		}
+	}
+
+	total, err := sumCostEstimates(estimates)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	list := make([]interface{}, 0, len(estimates))
+	for _, e := range estimates {
+		m := map[string]interface{}{
+			"workspace": e.Workspace,
+			"run_id":    e.RunID,
+			"status":    "",
+		}
+		if ce := e.CostEstimate; ce != nil {
+			m["status"] = string(ce.Status)
+			m["matched_resources_count"] = ce.MatchedResourcesCount
+			m["unmatched_resources_count"] = ce.UnmatchedResourcesCount
+		}
+		if e.finished() {
+			// Already parsed by sumCostEstimates.
+			m["prior_monthly_cost"], _ = parseCost(e.CostEstimate.PriorMonthlyCost)
+			m["proposed_monthly_cost"], _ = parseCost(e.CostEstimate.ProposedMonthlyCost)
+			m["delta_monthly_cost"], _ = parseCost(e.CostEstimate.DeltaMonthlyCost)
+		}
+		list = append(list, m)
+	}
+
+	id := org
+	if id == "" {
+		// Only runs were given, all of which are in the list.
+		var runIDs []string
+		for _, e := range estimates {
+			runIDs = append(runIDs, e.RunID)
+		}
+		id = strings.Join(runIDs, ",")
+	}
+
+	d.SetId(id)
+	values := map[string]interface{}{
+		"complete":              total.Complete,
+		"prior_monthly_cost":    total.Prior,
+		"proposed_monthly_cost": total.Proposed,
+		"delta_monthly_cost":    total.Delta,
+		"estimates":             list,
+	}
+	for k, v := range values {
+		if err := d.Set(k, v); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	return nil
+}
+
+// workspaceCostEstimate is the cost estimate of a run in a workspace. The
+// run ID and cost estimate are empty if the workspace has no estimate.
+type workspaceCostEstimate struct {
+	Workspace    string
+	RunID        string
+	CostEstimate *tfe.CostEstimate
+}
+
+func (e *workspaceCostEstimate) finished() bool {
+	return e.CostEstimate != nil && e.CostEstimate.Status == tfe.CostEstimateFinished
+}
+
+// costEstimateTotal is the sum of many cost estimates.
+type costEstimateTotal struct {
+	Prior    float64
+	Proposed float64
+	Delta    float64
+
+	// Complete is true if every estimate finished.
+	Complete bool
+}
+
+// sumCostEstimates adds up the finished estimates. Estimates that didn't
+// finish are left out and make the total incomplete.
+func sumCostEstimates(estimates []*workspaceCostEstimate) (costEstimateTotal, error) {
+	total := costEstimateTotal{Complete: true}
+	for _, e := range estimates {
+		if !e.finished() {
+			total.Complete = false
+			continue
+		}
+
+		for _, c := range []struct {
+			Value string
+			Total *float64
+		}{
+			{e.CostEstimate.PriorMonthlyCost, &total.Prior},
+			{e.CostEstimate.ProposedMonthlyCost, &total.Proposed},
+			{e.CostEstimate.DeltaMonthlyCost, &total.Delta},
+		} {
+			v, err := parseCost(c.Value)
+			if err != nil {
+				return total, fmt.Errorf(
+					"invalid cost %q in cost estimate of workspace %q: %s",
+					c.Value, e.Workspace, err)
+			}
+			*c.Total += v
+		}
+	}
+
+	return total, nil
+}
+
+// parseCost parses a cost from a cost estimate. Costs are decimal strings
+// in USD and are empty if there is nothing to estimate.
+func parseCost(v string) (float64, error) {
+	if v == "" {
+		return 0, nil
+	}
+
+	return strconv.ParseFloat(v, 64)
+}
+
+// costEstimateRunLimit is the number of most recent runs searched for a
+// finished cost estimate. Each estimate is another request, so we don't
+// search the entire history of workspaces that stopped estimating costs.
+const costEstimateRunLimit = 20
+
+// latestCostEstimate returns the cost estimate of the latest run in the
+// workspace whose cost estimate finished, searching only the most recent
+// costEstimateRunLimit runs.
+func latestCostEstimate(
+	ctx context.Context,
+	client *tfe.Client,
+	ws *tfe.Workspace,
+) (*workspaceCostEstimate, error) {
+	runs, err := listRuns(ctx, client, ws.ID, runFilter{}, costEstimateRunLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range runs {
+		if r.CostEstimate == nil {
+			continue
+		}
+
+		ce, err := client.CostEstimates.Read(ctx, r.CostEstimate.ID)
+		if err != nil {
+			return nil, err
+		}
+		if ce.Status == tfe.CostEstimateFinished {
+			return &workspaceCostEstimate{
+				Workspace:    ws.Name,
+				RunID:        r.ID,
+				CostEstimate: ce,
+			}, nil
+		}
+	}
+
+	return &workspaceCostEstimate{Workspace: ws.Name}, nil
+}
+
+var costEstimateDescriptions = map[string]string{
+	"organization": "The name of the Terraform Cloud organization that owns " +
+		"the workspaces. Required with the workspace selectors.",
+	"run_ids": "The IDs of runs to read the cost estimates of. The latest " +
+		"finished cost estimate of each selected workspace is read as well.",
+	"complete": "Whether every run and workspace has a finished cost " +
+		"estimate. If false, the totals leave out the missing estimates.",
+	"prior_monthly_cost":    "The total monthly cost before the runs, in USD.",
+	"proposed_monthly_cost": "The total monthly cost after the runs, in USD.",
+	"delta_monthly_cost":    "The total change in monthly cost, in USD.",
+	"estimates": "The cost estimate of each run, then of each selected " +
+		"workspace sorted by name.",
+	"workspace": "The name of the workspace.",
+	"run_id": "The ID of the run the estimate is for, or empty if the " +
+		"workspace has no finished cost estimate.",
+	"status": "The status of the cost estimate, such as `finished`, or " +
+		"empty if there is none.",
+	"estimates.prior_monthly_cost":    "The monthly cost before the run, in USD.",
+	"estimates.proposed_monthly_cost": "The monthly cost after the run, in USD.",
+	"estimates.delta_monthly_cost":    "The change in monthly cost, in USD.",
+	"matched_resources_count":         "The number of resources whose cost could be estimated.",
+	"unmatched_resources_count":       "The number of resources whose cost couldn't be estimated.",
+}
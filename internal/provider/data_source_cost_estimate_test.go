@@ -0,0 +1,92 @@
+package provider
+
+import (
+	"testing"
+
+	tfe "github.com/hashicorp/go-tfe"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestSumCostEstimates(t *testing.T) {
+	estimates := []*workspaceCostEstimate{
+		{
+			Workspace: "A",
+			RunID:     "run-a",
+			CostEstimate: &tfe.CostEstimate{
+				Status:              tfe.CostEstimateFinished,
+				PriorMonthlyCost:    "10.5",
+				ProposedMonthlyCost: "12.5",
+				DeltaMonthlyCost:    "2.0",
+			},
+		},
+		{
+			Workspace: "B",
+			RunID:     "run-b",
+			CostEstimate: &tfe.CostEstimate{
+				Status:              tfe.CostEstimateFinished,
+				PriorMonthlyCost:    "0.0",
+				ProposedMonthlyCost: "3.25",
+				DeltaMonthlyCost:    "3.25",
+			},
+		},
+	}
+
+	total, err := sumCostEstimates(estimates)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	expected := costEstimateTotal{Prior: 10.5, Proposed: 15.75, Delta: 5.25, Complete: true}
+	if total != expected {
+		t.Fatalf("bad: %#v", total)
+	}
+
+	// Missing and unfinished estimates are left out.
+	estimates = append(estimates,
+		&workspaceCostEstimate{Workspace: "C"},
+		&workspaceCostEstimate{
+			Workspace: "D",
+			RunID:     "run-d",
+			CostEstimate: &tfe.CostEstimate{
+				Status:              tfe.CostEstimateErrored,
+				ProposedMonthlyCost: "not a number",
+			},
+		},
+	)
+	total, err = sumCostEstimates(estimates)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	expected.Complete = false
+	if total != expected {
+		t.Fatalf("bad: %#v", total)
+	}
+
+	// Invalid costs of finished estimates are an error.
+	estimates[3].CostEstimate.Status = tfe.CostEstimateFinished
+	if _, err := sumCostEstimates(estimates); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestAccDataSourceCostEstimate(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceCostEstimate,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.multispace_cost_estimate.all", "estimates.#", "4"),
+					resource.TestCheckResourceAttrSet("data.multispace_cost_estimate.all", "proposed_monthly_cost"),
+				),
+			},
+		},
+	})
+}
+
+const testAccDataSourceCostEstimate = `
+data "multispace_cost_estimate" "all" {
+  organization = "multispace-test"
+  workspaces   = ["root", "A", "B", "C"]
+}
+`
@@ -0,0 +1,303 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/hashicorp/go-hclog"
+	tfe "github.com/hashicorp/go-tfe"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func dataSourceDrift() *schema.Resource {
+	s := map[string]*schema.Schema{
+		"organization": {
+			Description: driftDescriptions["organization"],
+			Type:        schema.TypeString,
+			Required:    true,
+		},
+
+		"refresh_only": {
+			Description: driftDescriptions["refresh_only"],
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     true,
+		},
+
+		"max_parallelism": {
+			Description:  driftDescriptions["max_parallelism"],
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Default:      10,
+			ValidateFunc: validation.IntAtLeast(1),
+		},
+
+		"has_changes": {
+			Description: driftDescriptions["has_changes"],
+			Type:        schema.TypeBool,
+			Computed:    true,
+		},
+
+		"drifted_workspaces": {
+			Description: driftDescriptions["drifted_workspaces"],
+			Type:        schema.TypeList,
+			Computed:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+		},
+
+		"results": {
+			Description: driftDescriptions["results"],
+			Type:        schema.TypeList,
+			Computed:    true,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"workspace": {
+						Description: driftDescriptions["workspace"],
+						Type:        schema.TypeString,
+						Computed:    true,
+					},
+
+					"run_id": {
+						Description: driftDescriptions["run_id"],
+						Type:        schema.TypeString,
+						Computed:    true,
+					},
+
+					"has_changes": {
+						Description: driftDescriptions["results.has_changes"],
+						Type:        schema.TypeBool,
+						Computed:    true,
+					},
+
+					"changed_resources": {
+						Description: driftDescriptions["changed_resources"],
+						Type:        schema.TypeList,
+						Computed:    true,
+						Elem:        &schema.Schema{Type: schema.TypeString},
+					},
+				},
+			},
+		},
+	}
+	addWorkspaceSelectorSchema(s)
+
+	return &schema.Resource{
+		Description: "Check many workspaces for drift with plans that are discarded",
+
+		ReadContext: dataSourceDriftRead,
+
+		Schema: s,
+	}
+}
+
+func dataSourceDriftRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*providerMeta).client
+	org := d.Get("organization").(string)
+	refreshOnly := d.Get("refresh_only").(bool)
+
+	workspaces, err := selectWorkspaces(ctx, client, org, d)
+	if err != nil {
+		return diag.Errorf("Failed to retrieve workspaces: %s", err)
+	}
+
+	var (
+		wg      sync.WaitGroup
+		lock    sync.Mutex
+		diags   diag.Diagnostics
+		results = make([]*driftResult, len(workspaces))
+	)
+
+	sem := make(chan struct{}, d.Get("max_parallelism").(int))
+	for i, ws := range workspaces {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int, ws *tfe.Workspace) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			result, checkDiags := checkDrift(ctx, client, org, ws, refreshOnly)
+
+			lock.Lock()
+			defer lock.Unlock()
+			for _, checkDiag := range checkDiags {
+				checkDiag.Summary = fmt.Sprintf("%s: %s", ws.Name, checkDiag.Summary)
+				diags = append(diags, checkDiag)
+			}
+			results[i] = result
+		}(i, ws)
+	}
+	wg.Wait()
+	if diags.HasError() {
+		return diags
+	}
+
+	hasChanges := false
+	drifted := []string{}
+	list := make([]interface{}, 0, len(results))
+	for _, r := range results {
+		if r.HasChanges {
+			hasChanges = true
+			drifted = append(drifted, r.Workspace)
+		}
+
+		list = append(list, map[string]interface{}{
+			"workspace":         r.Workspace,
+			"run_id":            r.RunID,
+			"has_changes":       r.HasChanges,
+			"changed_resources": r.ChangedResources,
+		})
+	}
+
+	d.SetId(org)
+	values := map[string]interface{}{
+		"has_changes":        hasChanges,
+		"drifted_workspaces": drifted,
+		"results":            list,
+	}
+	for k, v := range values {
+		if err := d.Set(k, v); err != nil {
+			return append(diags, diag.FromErr(err)...)
+		}
+	}
+
+	return diags
+}
+
+// driftResult is the result of a drift check of a single workspace.
+type driftResult struct {
+	Workspace        string
+	RunID            string
+	HasChanges       bool
+	ChangedResources []string
+}
+
+// checkDrift queues a run in the workspace that is never applied, waits
+// for its plan, and discards it.
+func checkDrift(
+	ctx context.Context,
+	client *tfe.Client,
+	org string,
+	ws *tfe.Workspace,
+	refreshOnly bool,
+) (*driftResult, diag.Diagnostics) {
+	logger := logger.Named("drift").With(
+		"organization", org,
+		"workspace", ws.Name,
+	)
+
+	run, err := client.Runs.Create(ctx, tfe.RunCreateOptions{
+		Message:     tfe.String("terraform-provider-multispace drift check"),
+		Workspace:   ws,
+		RefreshOnly: tfe.Bool(refreshOnly),
+		AutoApply:   tfe.Bool(false),
+	})
+	if err != nil {
+		return nil, diag.FromErr(err)
+	}
+
+	// waitForRun may return a nil run on error so we keep the ID.
+	id := run.ID
+	result := &driftResult{Workspace: ws.Name, RunID: id}
+	logger = logger.With("run_id", id)
+	ctx = hclog.WithContext(ctx, logger)
+	logger.Info("drift check run created")
+
+	// We never apply the run, so we always get rid of it once we're done,
+	// even if we failed or were canceled while waiting.
+	defer func() {
+		if err := discardRun(context.Background(), client, id); err != nil {
+			logger.Warn("failed to discard drift check run", "error", err)
+		}
+	}()
+
+	run, diags := waitForRun(ctx, client, org, run, ws, true, []tfe.RunStatus{
+		tfe.RunPlanned,
+		tfe.RunPlannedAndFinished,
+		tfe.RunErrored,
+		tfe.RunCostEstimated,
+		tfe.RunPolicyChecked,
+		tfe.RunPolicySoftFailed,
+		tfe.RunPolicyOverride,
+	}, []tfe.RunStatus{
+		tfe.RunPending,
+		tfe.RunPlanQueued,
+		tfe.RunPlanning,
+		tfe.RunCostEstimating,
+		tfe.RunPolicyChecking,
+	})
+	if diags != nil {
+		return result, diags
+	}
+
+	if run.Status == tfe.RunErrored {
+		return result, diag.Errorf(
+			"Run %q errored during plan. Please open the web UI to view the error",
+			id)
+	}
+
+	result.ChangedResources = []string{}
+	if !run.HasChanges || run.Status == tfe.RunPlannedAndFinished {
+		logger.Info("plan finished, no changes", "status", run.Status)
+		return result, nil
+	}
+	result.HasChanges = true
+
+	if run.Plan == nil {
+		return result, diag.Errorf("Run %q has no plan", id)
+	}
+	raw, err := client.Plans.JSONOutput(ctx, run.Plan.ID)
+	if err != nil {
+		return result, diag.Errorf("Failed to retrieve JSON plan of run %q: %s", id, err)
+	}
+	plan, err := parsePlanJSON(raw)
+	if err != nil {
+		return result, diag.Errorf("Failed to parse JSON plan of run %q: %s", id, err)
+	}
+	result.ChangedResources = plan.changedAddresses()
+
+	logger.Info("plan finished with changes", "resources", len(result.ChangedResources))
+	return result, nil
+}
+
+// changedAddresses returns the addresses of the resources that changed
+// outside of Terraform or that the plan would change, sorted.
+func (p *planJSON) changedAddresses() []string {
+	seen := map[string]struct{}{}
+	for _, rc := range p.ResourceDrift {
+		seen[rc.Address] = struct{}{}
+	}
+	for _, rc := range p.ResourceChanges {
+		if len(rc.Change.Actions) == 1 && rc.Change.Actions[0] == "no-op" {
+			continue
+		}
+		seen[rc.Address] = struct{}{}
+	}
+
+	result := make([]string, 0, len(seen))
+	for addr := range seen {
+		result = append(result, addr)
+	}
+	sort.Strings(result)
+	return result
+}
+
+var driftDescriptions = map[string]string{
+	"organization": "The name of the Terraform Cloud organization that owns the workspaces.",
+	"refresh_only": "If true, the runs are refresh-only and only report " +
+		"changes made outside of Terraform. If false, the runs are normal " +
+		"plans and also report changes the configuration would make. " +
+		"Refresh-only runs require Terraform 0.15.4 or later in the workspaces.",
+	"max_parallelism":     "The maximum number of workspaces to check at the same time.",
+	"has_changes":         "Whether any of the workspaces has changes.",
+	"drifted_workspaces":  "The names of the workspaces with changes, sorted.",
+	"results":             "The result of each workspace, sorted by name.",
+	"workspace":           "The name of the workspace.",
+	"run_id":              "The ID of the discarded run used to check the workspace.",
+	"results.has_changes": "Whether the plan of the workspace has changes.",
+	"changed_resources": "The addresses of the resource instances that " +
+		"changed outside of Terraform or that the plan would change, sorted.",
+}
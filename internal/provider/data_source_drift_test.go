@@ -0,0 +1,84 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestPlanJSONChangedAddresses(t *testing.T) {
+	raw := []byte(`{
+  "format_version": "0.2",
+  "resource_drift": [
+    {
+      "address": "aws_security_group.web",
+      "mode": "managed",
+      "type": "aws_security_group",
+      "name": "web",
+      "change": {"actions": ["update"]}
+    }
+  ],
+  "resource_changes": [
+    {
+      "address": "aws_security_group.web",
+      "mode": "managed",
+      "type": "aws_security_group",
+      "name": "web",
+      "change": {"actions": ["update"]}
+    },
+    {
+      "address": "aws_vpc.main",
+      "mode": "managed",
+      "type": "aws_vpc",
+      "name": "main",
+      "change": {"actions": ["no-op"]}
+    },
+    {
+      "address": "aws_instance.app[0]",
+      "mode": "managed",
+      "type": "aws_instance",
+      "name": "app",
+      "change": {"actions": ["delete", "create"]}
+    }
+  ]
+}`)
+
+	plan, err := parsePlanJSON(raw)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := []string{"aws_instance.app[0]", "aws_security_group.web"}
+	if actual := plan.changedAddresses(); !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("bad: %#v", actual)
+	}
+}
+
+func TestAccDataSourceDrift(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceDrift,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.multispace_drift.root", "results.#", "1"),
+					resource.TestCheckResourceAttrSet("data.multispace_drift.root", "results.0.run_id"),
+				),
+			},
+		},
+	})
+}
+
+const testAccDataSourceDrift = `
+resource "multispace_run" "root" {
+  organization = "multispace-test"
+  workspace    = "root"
+}
+
+data "multispace_drift" "root" {
+  organization = "multispace-test"
+  workspaces   = [multispace_run.root.workspace]
+}
+`
@@ -0,0 +1,178 @@
+package provider
+
+import (
+	"context"
+	"time"
+
+	tfe "github.com/hashicorp/go-tfe"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceOrgQueue() *schema.Resource {
+	return &schema.Resource{
+		Description: "Organization run queue and capacity",
+
+		ReadContext: dataSourceOrgQueueRead,
+
+		Schema: map[string]*schema.Schema{
+			"organization": {
+				Description: orgQueueDescriptions["organization"],
+				Type:        schema.TypeString,
+				Required:    true,
+			},
+
+			"running": {
+				Description: orgQueueDescriptions["running"],
+				Type:        schema.TypeInt,
+				Computed:    true,
+			},
+
+			"pending": {
+				Description: orgQueueDescriptions["pending"],
+				Type:        schema.TypeInt,
+				Computed:    true,
+			},
+
+			"runs": {
+				Description: orgQueueDescriptions["runs"],
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"run_id": {
+							Description: orgQueueDescriptions["runs.run_id"],
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+
+						"workspace": {
+							Description: orgQueueDescriptions["runs.workspace"],
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+
+						"status": {
+							Description: orgQueueDescriptions["runs.status"],
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+
+						"position": {
+							Description: orgQueueDescriptions["runs.position"],
+							Type:        schema.TypeInt,
+							Computed:    true,
+						},
+
+						"is_destroy": {
+							Description: orgQueueDescriptions["runs.is_destroy"],
+							Type:        schema.TypeBool,
+							Computed:    true,
+						},
+
+						"created_at": {
+							Description: orgQueueDescriptions["runs.created_at"],
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceOrgQueueRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*providerMeta).client
+	org := d.Get("organization").(string)
+
+	c, err := client.Organizations.Capacity(ctx, org)
+	if err != nil {
+		return diag.Errorf("Failed to retrieve capacity: %s", err)
+	}
+
+	queue, err := listRunQueue(ctx, client, org)
+	if err != nil {
+		return diag.Errorf("Failed to retrieve queue: %s", err)
+	}
+
+	// The queue only has the IDs of the workspaces so we look up their
+	// names, once per workspace.
+	names := map[string]string{}
+	runs := make([]interface{}, 0, len(queue))
+	for _, r := range queue {
+		var name string
+		if r.Workspace != nil {
+			name = r.Workspace.Name
+			if name == "" {
+				if _, ok := names[r.Workspace.ID]; !ok {
+					ws, err := client.Workspaces.ReadByID(ctx, r.Workspace.ID)
+					if err != nil {
+						return diag.FromErr(err)
+					}
+					names[r.Workspace.ID] = ws.Name
+				}
+				name = names[r.Workspace.ID]
+			}
+		}
+
+		runs = append(runs, map[string]interface{}{
+			"run_id":     r.ID,
+			"workspace":  name,
+			"status":     string(r.Status),
+			"position":   r.PositionInQueue,
+			"is_destroy": r.IsDestroy,
+			"created_at": r.CreatedAt.Format(time.RFC3339),
+		})
+	}
+
+	d.SetId(org)
+	if err := d.Set("running", c.Running); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("pending", c.Pending); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("runs", runs); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
+
+// listRunQueue returns every run in the organization's run queue,
+// reading every page.
+func listRunQueue(ctx context.Context, client *tfe.Client, org string) ([]*tfe.Run, error) {
+	var result []*tfe.Run
+	options := tfe.RunQueueOptions{}
+	for {
+		rq, err := client.Organizations.RunQueue(ctx, org, options)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, rq.Items...)
+
+		// Exit the loop when we've seen all pages.
+		if rq.Pagination == nil || rq.CurrentPage >= rq.TotalPages {
+			break
+		}
+
+		// Update the page number to get the next page.
+		options.PageNumber = rq.NextPage
+	}
+
+	return result, nil
+}
+
+var orgQueueDescriptions = map[string]string{
+	"organization":    "The name of the Terraform Cloud organization.",
+	"running":         "The number of runs currently running in the organization.",
+	"pending":         "The number of runs waiting to start in the organization.",
+	"runs":            "The runs in the organization's run queue.",
+	"runs.run_id":     "The ID of the run.",
+	"runs.workspace":  "The name of the workspace of the run.",
+	"runs.status":     "The status of the run.",
+	"runs.position":   "The position of the run in the organization's queue.",
+	"runs.is_destroy": "Whether the run is a destroy.",
+	"runs.created_at": "The time the run was created, in RFC 3339 format.",
+}
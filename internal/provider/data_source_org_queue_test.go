@@ -0,0 +1,29 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccDataSourceOrgQueue(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceOrgQueue,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet("data.multispace_org_queue.q", "running"),
+					resource.TestCheckResourceAttrSet("data.multispace_org_queue.q", "pending"),
+				),
+			},
+		},
+	})
+}
+
+const testAccDataSourceOrgQueue = `
+data "multispace_org_queue" "q" {
+  organization = "multispace-test"
+}
+`
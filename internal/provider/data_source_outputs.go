@@ -0,0 +1,161 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+
+	tfe "github.com/hashicorp/go-tfe"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceOutputs() *schema.Resource {
+	s := map[string]*schema.Schema{
+		"organization": {
+			Description: outputsDescriptions["organization"],
+			Type:        schema.TypeString,
+			Required:    true,
+		},
+
+		"workspace_names": {
+			Description: outputsDescriptions["workspace_names"],
+			Type:        schema.TypeList,
+			Computed:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+		},
+
+		"outputs": {
+			Description: outputsDescriptions["outputs"],
+			Type:        schema.TypeMap,
+			Computed:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+		},
+
+		"sensitive_outputs": {
+			Description: outputsDescriptions["sensitive_outputs"],
+			Type:        schema.TypeMap,
+			Computed:    true,
+			Sensitive:   true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+		},
+	}
+	addWorkspaceSelectorSchema(s)
+
+	return &schema.Resource{
+		Description: "Current state outputs of many workspaces",
+
+		ReadContext: dataSourceOutputsRead,
+
+		Schema: s,
+	}
+}
+
+func dataSourceOutputsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*providerMeta).client
+	org := d.Get("organization").(string)
+
+	workspaces, err := selectWorkspaces(ctx, client, org, d)
+	if err != nil {
+		return diag.Errorf("Failed to retrieve workspaces: %s", err)
+	}
+
+	names := make([]string, 0, len(workspaces))
+	outputs := map[string]interface{}{}
+	sensitiveOutputs := map[string]interface{}{}
+	for _, ws := range workspaces {
+		names = append(names, ws.Name)
+
+		values, err := currentStateOutputs(ctx, client, ws.ID)
+		if err != nil {
+			return diag.Errorf(
+				"Failed to retrieve outputs of workspace %q: %s", ws.Name, err)
+		}
+
+		public, sensitive := splitOutputs(values)
+		if outputs[ws.Name], err = encodeOutputs(public); err != nil {
+			return diag.FromErr(err)
+		}
+		if sensitiveOutputs[ws.Name], err = encodeOutputs(sensitive); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	d.SetId(org)
+	if err := d.Set("workspace_names", names); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("outputs", outputs); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("sensitive_outputs", sensitiveOutputs); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
+
+// currentStateOutputs returns the outputs of the current state version of
+// the workspace. If the workspace has no state, this returns no outputs.
+func currentStateOutputs(
+	ctx context.Context,
+	client *tfe.Client,
+	workspaceID string,
+) ([]*tfe.StateVersionOutput, error) {
+	sv, err := client.StateVersions.CurrentWithOptions(ctx, workspaceID, &tfe.StateVersionCurrentOptions{
+		Include: "outputs",
+	})
+	if err == tfe.ErrResourceNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Sensitive values aren't included with the state version so we have
+	// to read them one at a time.
+	for i, o := range sv.Outputs {
+		if o.Sensitive && o.Value == nil {
+			if sv.Outputs[i], err = client.StateVersionOutputs.Read(ctx, o.ID); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return sv.Outputs, nil
+}
+
+// splitOutputs splits outputs into a map of non-sensitive and a map of
+// sensitive values keyed by name.
+func splitOutputs(outputs []*tfe.StateVersionOutput) (map[string]interface{}, map[string]interface{}) {
+	public := map[string]interface{}{}
+	sensitive := map[string]interface{}{}
+	for _, o := range outputs {
+		if o.Sensitive {
+			sensitive[o.Name] = o.Value
+		} else {
+			public[o.Name] = o.Value
+		}
+	}
+
+	return public, sensitive
+}
+
+// encodeOutputs encodes output values as a JSON object.
+func encodeOutputs(values map[string]interface{}) (string, error) {
+	raw, err := json.Marshal(values)
+	if err != nil {
+		return "", err
+	}
+
+	return string(raw), nil
+}
+
+var outputsDescriptions = map[string]string{
+	"organization":    "The name of the Terraform Cloud organization that owns the workspaces.",
+	"workspace_names": "The names of the selected workspaces, sorted.",
+	"outputs": "The non-sensitive outputs of each selected workspace, keyed by " +
+		"workspace name. Each value is a JSON object of output values keyed " +
+		"by output name, to be decoded with `jsondecode`.",
+	"sensitive_outputs": "The sensitive outputs of each selected workspace, in " +
+		"the same format as `outputs`.",
+}
@@ -0,0 +1,65 @@
+package provider
+
+import (
+	"testing"
+
+	tfe "github.com/hashicorp/go-tfe"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestSplitOutputs(t *testing.T) {
+	public, sensitive := splitOutputs([]*tfe.StateVersionOutput{
+		{Name: "vpc_id", Value: "vpc-123"},
+		{Name: "subnets", Value: []interface{}{"a", "b"}},
+		{Name: "password", Value: "hunter2", Sensitive: true},
+	})
+
+	actual, err := encodeOutputs(public)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if expected := `{"subnets":["a","b"],"vpc_id":"vpc-123"}`; actual != expected {
+		t.Fatalf("expected %s, got %s", expected, actual)
+	}
+
+	actual, err = encodeOutputs(sensitive)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if expected := `{"password":"hunter2"}`; actual != expected {
+		t.Fatalf("expected %s, got %s", expected, actual)
+	}
+
+	// No outputs is an empty object so jsondecode always works.
+	actual, err = encodeOutputs(map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if actual != "{}" {
+		t.Fatalf("expected {}, got %s", actual)
+	}
+}
+
+func TestAccDataSourceOutputs(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceOutputs,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.multispace_outputs.all", "workspace_names.#", "2"),
+					resource.TestCheckResourceAttrSet("data.multispace_outputs.all", "outputs.B"),
+					resource.TestCheckResourceAttrSet("data.multispace_outputs.all", "outputs.C"),
+				),
+			},
+		},
+	})
+}
+
+const testAccDataSourceOutputs = `
+data "multispace_outputs" "all" {
+  organization = "multispace-test"
+  workspaces   = ["B", "C"]
+}
+`
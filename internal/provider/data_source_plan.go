@@ -0,0 +1,166 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourcePlan() *schema.Resource {
+	return &schema.Resource{
+		Description: "JSON execution plan of a run",
+
+		ReadContext: dataSourcePlanRead,
+
+		Schema: map[string]*schema.Schema{
+			"run_id": {
+				Description: planDescriptions["run_id"],
+				Type:        schema.TypeString,
+				Required:    true,
+			},
+
+			"resource_changes": {
+				Description: planDescriptions["resource_changes"],
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"address": {
+							Description: planDescriptions["address"],
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+
+						"module_address": {
+							Description: planDescriptions["module_address"],
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+
+						"mode": {
+							Description: planDescriptions["mode"],
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+
+						"type": {
+							Description: planDescriptions["type"],
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+
+						"name": {
+							Description: planDescriptions["name"],
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+
+						"provider_name": {
+							Description: planDescriptions["provider_name"],
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+
+						"actions": {
+							Description: planDescriptions["actions"],
+							Type:        schema.TypeList,
+							Computed:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourcePlanRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*providerMeta).client
+	id := d.Get("run_id").(string)
+
+	run, err := client.Runs.Read(ctx, id)
+	if err != nil {
+		return diag.Errorf("Failed to retrieve run %q: %s", id, err)
+	}
+	if run.Plan == nil {
+		return diag.Errorf("Run %q has no plan", id)
+	}
+
+	raw, err := client.Plans.JSONOutput(ctx, run.Plan.ID)
+	if err != nil {
+		return diag.Errorf("Failed to retrieve JSON plan of run %q: %s", id, err)
+	}
+
+	plan, err := parsePlanJSON(raw)
+	if err != nil {
+		return diag.Errorf("Failed to parse JSON plan of run %q: %s", id, err)
+	}
+
+	changes := make([]interface{}, 0, len(plan.ResourceChanges))
+	for _, rc := range plan.ResourceChanges {
+		changes = append(changes, map[string]interface{}{
+			"address":        rc.Address,
+			"module_address": rc.ModuleAddress,
+			"mode":           rc.Mode,
+			"type":           rc.Type,
+			"name":           rc.Name,
+			"provider_name":  rc.ProviderName,
+			"actions":        rc.Change.Actions,
+		})
+	}
+
+	d.SetId(run.Plan.ID)
+	if err := d.Set("resource_changes", changes); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
+
+// planJSON is the subset of the JSON plan format that we use.
+type planJSON struct {
+	ResourceChanges []planResourceChange `json:"resource_changes"`
+
+	// ResourceDrift are the changes made outside of Terraform that were
+	// detected while refreshing.
+	ResourceDrift []planResourceChange `json:"resource_drift"`
+}
+
+// planResourceChange is a planned change to a single resource instance.
+type planResourceChange struct {
+	Address       string `json:"address"`
+	ModuleAddress string `json:"module_address"`
+	Mode          string `json:"mode"`
+	Type          string `json:"type"`
+	Name          string `json:"name"`
+	ProviderName  string `json:"provider_name"`
+	Change        struct {
+		Actions []string `json:"actions"`
+	} `json:"change"`
+}
+
+// parsePlanJSON parses the output of "terraform show -json" for a plan.
+func parsePlanJSON(raw []byte) (*planJSON, error) {
+	var result planJSON
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+var planDescriptions = map[string]string{
+	"run_id": "The ID of the run, such as the `id` of a `multispace_run`.",
+	"resource_changes": "The planned change of every resource instance in the " +
+		"plan, including those with no changes.",
+	"address":        "The full address of the resource instance.",
+	"module_address": "The address of the module containing the resource, or empty for the root module.",
+	"mode":           "Either `managed` for resources or `data` for data sources.",
+	"type":           "The resource type.",
+	"name":           "The resource name.",
+	"provider_name":  "The provider of the resource.",
+	"actions": "The actions that will be taken on the resource, such as " +
+		"`[\"create\"]`, `[\"no-op\"]`, or `[\"delete\", \"create\"]` for a replacement.",
+}
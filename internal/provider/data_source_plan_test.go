@@ -0,0 +1,80 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestParsePlanJSON(t *testing.T) {
+	raw := []byte(`{
+  "format_version": "0.2",
+  "terraform_version": "1.0.11",
+  "resource_changes": [
+    {
+      "address": "module.db.aws_db_instance.main",
+      "module_address": "module.db",
+      "mode": "managed",
+      "type": "aws_db_instance",
+      "name": "main",
+      "provider_name": "registry.terraform.io/hashicorp/aws",
+      "change": {"actions": ["delete", "create"], "before": {}, "after": {}}
+    },
+    {
+      "address": "aws_vpc.main",
+      "mode": "managed",
+      "type": "aws_vpc",
+      "name": "main",
+      "provider_name": "registry.terraform.io/hashicorp/aws",
+      "change": {"actions": ["no-op"]}
+    }
+  ]
+}`)
+
+	plan, err := parsePlanJSON(raw)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if len(plan.ResourceChanges) != 2 {
+		t.Fatalf("expected 2 changes, got %d", len(plan.ResourceChanges))
+	}
+
+	rc := plan.ResourceChanges[0]
+	if rc.Address != "module.db.aws_db_instance.main" || rc.ModuleAddress != "module.db" || rc.Type != "aws_db_instance" {
+		t.Fatalf("bad change: %#v", rc)
+	}
+	if !reflect.DeepEqual(rc.Change.Actions, []string{"delete", "create"}) {
+		t.Fatalf("bad actions: %#v", rc.Change.Actions)
+	}
+
+	if rc := plan.ResourceChanges[1]; rc.ModuleAddress != "" || !reflect.DeepEqual(rc.Change.Actions, []string{"no-op"}) {
+		t.Fatalf("bad change: %#v", rc)
+	}
+}
+
+func TestAccDataSourcePlan(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourcePlan,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet("data.multispace_plan.root", "resource_changes.#"),
+				),
+			},
+		},
+	})
+}
+
+const testAccDataSourcePlan = `
+resource "multispace_run" "root" {
+  organization = "multispace-test"
+  workspace    = "root"
+}
+
+data "multispace_plan" "root" {
+  run_id = multispace_run.root.id
+}
+`
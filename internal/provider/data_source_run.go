@@ -0,0 +1,399 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tfe "github.com/hashicorp/go-tfe"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func dataSourceRun() *schema.Resource {
+	return &schema.Resource{
+		Description: "Look up a run by ID or the latest run in a workspace",
+
+		ReadContext: dataSourceRunRead,
+
+		Schema: map[string]*schema.Schema{
+			"run_id": {
+				Description:  runDataDescriptions["run_id"],
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"run_id", "workspace"},
+			},
+
+			"organization": {
+				Description:  runDataDescriptions["organization"],
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				RequiredWith: []string{"workspace"},
+			},
+
+			"workspace": {
+				Description:  runDataDescriptions["workspace"],
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				RequiredWith: []string{"organization"},
+			},
+
+			"filter_status": {
+				Description: runDataDescriptions["filter_status"],
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+
+			"filter_is_destroy": {
+				Description: runDataDescriptions["filter_is_destroy"],
+				Type:        schema.TypeBool,
+				Optional:    true,
+			},
+
+			"filter_source": {
+				Description: runDataDescriptions["filter_source"],
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+
+			"message_prefix": {
+				Description: runDataDescriptions["message_prefix"],
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+
+			"message_contains": {
+				Description: runDataDescriptions["message_contains"],
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+
+			"created_after": {
+				Description:  runDataDescriptions["created_after"],
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.IsRFC3339Time,
+			},
+
+			"created_before": {
+				Description:  runDataDescriptions["created_before"],
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.IsRFC3339Time,
+			},
+
+			"status": {
+				Description: runDataDescriptions["status"],
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+
+			"is_destroy": {
+				Description: runDataDescriptions["is_destroy"],
+				Type:        schema.TypeBool,
+				Computed:    true,
+			},
+
+			"source": {
+				Description: runDataDescriptions["source"],
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+
+			"message": {
+				Description: runDataDescriptions["message"],
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+
+			"has_changes": {
+				Description: runDataDescriptions["has_changes"],
+				Type:        schema.TypeBool,
+				Computed:    true,
+			},
+
+			"resource_additions": {
+				Description: runDataDescriptions["resource_additions"],
+				Type:        schema.TypeInt,
+				Computed:    true,
+			},
+
+			"resource_changes": {
+				Description: runDataDescriptions["resource_changes"],
+				Type:        schema.TypeInt,
+				Computed:    true,
+			},
+
+			"resource_destructions": {
+				Description: runDataDescriptions["resource_destructions"],
+				Type:        schema.TypeInt,
+				Computed:    true,
+			},
+
+			"created_at": {
+				Description: runDataDescriptions["created_at"],
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+
+			"status_timestamps": {
+				Description: runDataDescriptions["status_timestamps"],
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+
+			"configuration_version_id": {
+				Description: runDataDescriptions["configuration_version_id"],
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+
+			"url": {
+				Description: runDataDescriptions["url"],
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func dataSourceRunRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*providerMeta).client
+
+	var run *tfe.Run
+	if id := d.Get("run_id").(string); id != "" {
+		var err error
+		run, err = client.Runs.Read(ctx, id)
+		if err != nil {
+			return diag.Errorf("Failed to retrieve run %q: %s", id, err)
+		}
+	} else {
+		org := d.Get("organization").(string)
+		workspace := d.Get("workspace").(string)
+		ws, err := client.Workspaces.Read(ctx, org, workspace)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+
+		filter := runFilterFromResourceData(d, "filter_")
+		run, err = findLatestRun(ctx, client, ws.ID, filter)
+		if err != nil {
+			return diag.Errorf("Failed to retrieve runs: %s", err)
+		}
+		if run == nil {
+			return diag.Errorf("No run in workspace %q matches the filters", workspace)
+		}
+	}
+
+	// The run only has the IDs of its relations so we read the workspace
+	// for the URL and the plan for the resource counts.
+	if run.Workspace == nil {
+		return diag.Errorf("Run %q has no workspace", run.ID)
+	}
+	ws, err := client.Workspaces.ReadByID(ctx, run.Workspace.ID)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	var plan *tfe.Plan
+	if run.Plan != nil {
+		if plan, err = client.Plans.Read(ctx, run.Plan.ID); err != nil {
+			return diag.Errorf("Failed to retrieve plan: %s", err)
+		}
+	}
+
+	org := d.Get("organization").(string)
+	if ws.Organization != nil {
+		org = ws.Organization.Name
+	}
+
+	d.SetId(run.ID)
+	values := map[string]interface{}{
+		"organization":      org,
+		"run_id":            run.ID,
+		"workspace":         ws.Name,
+		"status":            string(run.Status),
+		"is_destroy":        run.IsDestroy,
+		"source":            string(run.Source),
+		"message":           run.Message,
+		"has_changes":       run.HasChanges,
+		"created_at":        run.CreatedAt.Format(time.RFC3339),
+		"status_timestamps": flattenRunStatusTimestamps(run.StatusTimestamps),
+		"url": meta.(*providerMeta).webURL(fmt.Sprintf(
+			"app/%s/workspaces/%s/runs/%s", org, ws.Name, run.ID)),
+	}
+	if run.ConfigurationVersion != nil {
+		values["configuration_version_id"] = run.ConfigurationVersion.ID
+	}
+	if plan != nil {
+		values["resource_additions"] = plan.ResourceAdditions
+		values["resource_changes"] = plan.ResourceChanges
+		values["resource_destructions"] = plan.ResourceDestructions
+	}
+	for k, v := range values {
+		if err := d.Set(k, v); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	return nil
+}
+
+// runFilter selects runs in a workspace. Empty fields match every run.
+type runFilter struct {
+	Status          string
+	IsDestroy       *bool
+	Source          string
+	MessagePrefix   string
+	MessageContains string
+	CreatedAfter    time.Time
+	CreatedBefore   time.Time
+
+	// MaxPages, if positive, limits how many pages of runs are searched.
+	// This isn't a filter of the runs themselves so it isn't in matches.
+	MaxPages int
+}
+
+// runFilterFromResourceData reads the filter arguments. The prefix is
+// prepended to the status, is_destroy, and source keys since a data source
+// may also use those for the attributes of the run it found.
+func runFilterFromResourceData(d *schema.ResourceData, prefix string) runFilter {
+	f := runFilter{
+		Status:          d.Get(prefix + "status").(string),
+		Source:          d.Get(prefix + "source").(string),
+		MessagePrefix:   d.Get("message_prefix").(string),
+		MessageContains: d.Get("message_contains").(string),
+	}
+
+	// GetOkExists is deprecated but it is the only way to tell false from
+	// unset for a bool.
+	if v, ok := d.GetOkExists(prefix + "is_destroy"); ok {
+		b := v.(bool)
+		f.IsDestroy = &b
+	}
+	if v, ok := d.GetOk("created_after"); ok {
+		// Validated by the schema.
+		f.CreatedAfter, _ = time.Parse(time.RFC3339, v.(string))
+	}
+	if v, ok := d.GetOk("created_before"); ok {
+		// Validated by the schema.
+		f.CreatedBefore, _ = time.Parse(time.RFC3339, v.(string))
+	}
+
+	return f
+}
+
+// matches returns true if the run matches every field of the filter.
+func (f runFilter) matches(r *tfe.Run) bool {
+	if f.Status != "" && string(r.Status) != f.Status {
+		return false
+	}
+	if f.IsDestroy != nil && r.IsDestroy != *f.IsDestroy {
+		return false
+	}
+	if f.Source != "" && string(r.Source) != f.Source {
+		return false
+	}
+	if f.MessagePrefix != "" && !strings.HasPrefix(r.Message, f.MessagePrefix) {
+		return false
+	}
+	if f.MessageContains != "" && !strings.Contains(r.Message, f.MessageContains) {
+		return false
+	}
+	if r.CreatedAt.Before(f.CreatedAfter) {
+		return false
+	}
+	if !f.CreatedBefore.IsZero() && !r.CreatedAt.Before(f.CreatedBefore) {
+		return false
+	}
+
+	return true
+}
+
+// findLatestRun returns the latest run in the workspace matching the
+// filter, or nil if no run matches.
+func findLatestRun(
+	ctx context.Context,
+	client *tfe.Client,
+	workspaceID string,
+	filter runFilter,
+) (*tfe.Run, error) {
+	runs, err := listRuns(ctx, client, workspaceID, filter, 1)
+	if err != nil || len(runs) == 0 {
+		return nil, err
+	}
+
+	return runs[0], nil
+}
+
+// flattenRunStatusTimestamps returns the timestamps that are set, keyed
+// by status, in RFC 3339 format.
+func flattenRunStatusTimestamps(ts *tfe.RunStatusTimestamps) map[string]interface{} {
+	result := map[string]interface{}{}
+	if ts == nil {
+		return result
+	}
+
+	for k, v := range map[string]time.Time{
+		"applied":              ts.AppliedAt,
+		"apply_queued":         ts.ApplyQueuedAt,
+		"applying":             ts.ApplyingAt,
+		"canceled":             ts.CanceledAt,
+		"confirmed":            ts.ConfirmedAt,
+		"cost_estimated":       ts.CostEstimatedAt,
+		"cost_estimating":      ts.CostEstimatingAt,
+		"discarded":            ts.DiscardedAt,
+		"errored":              ts.ErroredAt,
+		"force_canceled":       ts.ForceCanceledAt,
+		"plan_queueable":       ts.PlanQueueableAt,
+		"plan_queued":          ts.PlanQueuedAt,
+		"planned_and_finished": ts.PlannedAndFinishedAt,
+		"planned":              ts.PlannedAt,
+		"planning":             ts.PlanningAt,
+		"policy_checked":       ts.PolicyCheckedAt,
+		"policy_soft_failed":   ts.PolicySoftFailedAt,
+	} {
+		if !v.IsZero() {
+			result[k] = v.Format(time.RFC3339)
+		}
+	}
+
+	return result
+}
+
+var runDataDescriptions = map[string]string{
+	"run_id": "The ID of the run to look up. Either this or `workspace` must " +
+		"be set.",
+	"organization": "The name of the Terraform Cloud organization that owns " +
+		"the workspace. Required with `workspace`.",
+	"workspace": "The name of the workspace to find the latest run in. The " +
+		"filters below select which runs are considered.",
+	"filter_status": "Only consider runs with this status, such as `applied`.",
+	"filter_is_destroy": "Only consider destroy runs if true, or only " +
+		"non-destroy runs if false.",
+	"filter_source": "Only consider runs from this source, such as " +
+		"`tfe-api` or `tfe-run-trigger`.",
+	"message_prefix":        "Only consider runs whose message starts with this prefix.",
+	"message_contains":      "Only consider runs whose message contains this string.",
+	"created_after":         "Only consider runs created after this time, in RFC 3339 format.",
+	"created_before":        "Only consider runs created before this time, in RFC 3339 format.",
+	"status":                "The status of the run.",
+	"is_destroy":            "Whether the run is a destroy.",
+	"source":                "The source of the run, such as `tfe-api`.",
+	"message":               "The message of the run.",
+	"has_changes":           "Whether the plan of the run has changes.",
+	"resource_additions":    "The number of resources the plan adds.",
+	"resource_changes":      "The number of resources the plan changes.",
+	"resource_destructions": "The number of resources the plan destroys.",
+	"created_at":            "The time the run was created, in RFC 3339 format.",
+	"status_timestamps": "The time the run entered each status it has been " +
+		"in, keyed by status, in RFC 3339 format.",
+	"configuration_version_id": "The ID of the configuration version of the run.",
+	"url":                      "The URL of the run in the web UI.",
+}
@@ -0,0 +1,109 @@
+package provider
+
+import (
+	"testing"
+	"time"
+
+	tfe "github.com/hashicorp/go-tfe"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestRunFilterMatches(t *testing.T) {
+	created := time.Date(2021, 11, 23, 12, 0, 0, 0, time.UTC)
+	run := &tfe.Run{
+		Status:    tfe.RunApplied,
+		Source:    "tfe-api",
+		Message:   "terraform-provider-multispace on 2021-11-23",
+		CreatedAt: created,
+	}
+
+	yes, no := true, false
+	cases := []struct {
+		Name     string
+		Filter   runFilter
+		Expected bool
+	}{
+		{"empty", runFilter{}, true},
+		{"status", runFilter{Status: "applied"}, true},
+		{"status mismatch", runFilter{Status: "errored"}, false},
+		{"not destroy", runFilter{IsDestroy: &no}, true},
+		{"destroy", runFilter{IsDestroy: &yes}, false},
+		{"source", runFilter{Source: "tfe-api"}, true},
+		{"source mismatch", runFilter{Source: "tfe-run-trigger"}, false},
+		{"message prefix", runFilter{MessagePrefix: "terraform-provider-multispace"}, true},
+		{"message prefix mismatch", runFilter{MessagePrefix: "Queued manually"}, false},
+		{"created after", runFilter{CreatedAfter: created.Add(-time.Hour)}, true},
+		{"created after mismatch", runFilter{CreatedAfter: created.Add(time.Hour)}, false},
+		{"message contains", runFilter{MessageContains: "multispace"}, true},
+		{"message contains mismatch", runFilter{MessageContains: "Queued manually"}, false},
+		{"before end of range", runFilter{CreatedBefore: created.Add(time.Hour)}, true},
+		{"after end of range", runFilter{CreatedBefore: created.Add(-time.Hour)}, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			if actual := tc.Filter.matches(run); actual != tc.Expected {
+				t.Fatalf("expected %v, got %v", tc.Expected, actual)
+			}
+		})
+	}
+}
+
+func TestFlattenRunStatusTimestamps(t *testing.T) {
+	actual := flattenRunStatusTimestamps(&tfe.RunStatusTimestamps{
+		PlanQueuedAt: time.Date(2021, 11, 23, 12, 0, 0, 0, time.UTC),
+		AppliedAt:    time.Date(2021, 11, 23, 12, 5, 0, 0, time.UTC),
+	})
+
+	if len(actual) != 2 {
+		t.Fatalf("expected 2 timestamps, got %#v", actual)
+	}
+	if actual["plan_queued"] != "2021-11-23T12:00:00Z" || actual["applied"] != "2021-11-23T12:05:00Z" {
+		t.Fatalf("bad: %#v", actual)
+	}
+
+	if len(flattenRunStatusTimestamps(nil)) != 0 {
+		t.Fatal("expected no timestamps")
+	}
+}
+
+func TestAccDataSourceRun(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceRun,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair(
+						"data.multispace_run.latest", "run_id",
+						"multispace_run.root", "id",
+					),
+					resource.TestCheckResourceAttrPair(
+						"data.multispace_run.by_id", "workspace",
+						"multispace_run.root", "workspace",
+					),
+				),
+			},
+		},
+	})
+}
+
+const testAccDataSourceRun = `
+resource "multispace_run" "root" {
+  organization = "multispace-test"
+  workspace    = "root"
+}
+
+data "multispace_run" "latest" {
+  organization      = "multispace-test"
+  workspace         = "root"
+  filter_is_destroy = false
+  message_prefix    = "terraform-provider-multispace"
+  depends_on        = [multispace_run.root]
+}
+
+data "multispace_run" "by_id" {
+  run_id = multispace_run.root.id
+}
+`
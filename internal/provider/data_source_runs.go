@@ -0,0 +1,252 @@
+package provider
+
+import (
+	"context"
+	"time"
+
+	tfe "github.com/hashicorp/go-tfe"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func dataSourceRuns() *schema.Resource {
+	return &schema.Resource{
+		Description: "List the runs in a workspace",
+
+		ReadContext: dataSourceRunsRead,
+
+		Schema: map[string]*schema.Schema{
+			"organization": {
+				Description: runsDescriptions["organization"],
+				Type:        schema.TypeString,
+				Required:    true,
+			},
+
+			"workspace": {
+				Description: runsDescriptions["workspace"],
+				Type:        schema.TypeString,
+				Required:    true,
+			},
+
+			"status": {
+				Description: runsDescriptions["status"],
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+
+			"is_destroy": {
+				Description: runsDescriptions["is_destroy"],
+				Type:        schema.TypeBool,
+				Optional:    true,
+			},
+
+			"source": {
+				Description: runsDescriptions["source"],
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+
+			"message_prefix": {
+				Description: runsDescriptions["message_prefix"],
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+
+			"message_contains": {
+				Description: runsDescriptions["message_contains"],
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+
+			"created_after": {
+				Description:  runsDescriptions["created_after"],
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.IsRFC3339Time,
+			},
+
+			"created_before": {
+				Description:  runsDescriptions["created_before"],
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.IsRFC3339Time,
+			},
+
+			"limit": {
+				Description:  runsDescriptions["limit"],
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+
+			"runs": {
+				Description: runsDescriptions["runs"],
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Description: runsDescriptions["id"],
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+
+						"status": {
+							Description: runsDescriptions["runs.status"],
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+
+						"is_destroy": {
+							Description: runsDescriptions["runs.is_destroy"],
+							Type:        schema.TypeBool,
+							Computed:    true,
+						},
+
+						"source": {
+							Description: runsDescriptions["runs.source"],
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+
+						"message": {
+							Description: runsDescriptions["message"],
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+
+						"has_changes": {
+							Description: runsDescriptions["has_changes"],
+							Type:        schema.TypeBool,
+							Computed:    true,
+						},
+
+						"created_at": {
+							Description: runsDescriptions["created_at"],
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+
+						"status_timestamps": {
+							Description: runsDescriptions["status_timestamps"],
+							Type:        schema.TypeMap,
+							Computed:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceRunsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*providerMeta).client
+	org := d.Get("organization").(string)
+	workspace := d.Get("workspace").(string)
+
+	ws, err := client.Workspaces.Read(ctx, org, workspace)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	filter := runFilterFromResourceData(d, "")
+	list, err := listRuns(ctx, client, ws.ID, filter, d.Get("limit").(int))
+	if err != nil {
+		return diag.Errorf("Failed to retrieve runs: %s", err)
+	}
+
+	runs := make([]interface{}, 0, len(list))
+	for _, r := range list {
+		runs = append(runs, map[string]interface{}{
+			"id":                r.ID,
+			"status":            string(r.Status),
+			"is_destroy":        r.IsDestroy,
+			"source":            string(r.Source),
+			"message":           r.Message,
+			"has_changes":       r.HasChanges,
+			"created_at":        r.CreatedAt.Format(time.RFC3339),
+			"status_timestamps": flattenRunStatusTimestamps(r.StatusTimestamps),
+		})
+	}
+
+	d.SetId(ws.ID)
+	if err := d.Set("runs", runs); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
+
+// listRuns returns the runs in the workspace matching the filter, newest
+// first. If limit is positive, at most that many runs are returned.
+func listRuns(
+	ctx context.Context,
+	client *tfe.Client,
+	workspaceID string,
+	filter runFilter,
+	limit int,
+) ([]*tfe.Run, error) {
+	var result []*tfe.Run
+	options := tfe.RunListOptions{}
+	for {
+		rl, err := client.Runs.List(ctx, workspaceID, options)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, r := range rl.Items {
+			// Runs are listed newest first so nothing after this can match.
+			if r.CreatedAt.Before(filter.CreatedAfter) {
+				return result, nil
+			}
+			if !filter.matches(r) {
+				continue
+			}
+
+			result = append(result, r)
+			if limit > 0 && len(result) >= limit {
+				return result, nil
+			}
+		}
+
+		// Exit the loop when we've seen all pages.
+		if rl.Pagination == nil || rl.CurrentPage >= rl.TotalPages {
+			break
+		}
+		if filter.MaxPages > 0 && rl.CurrentPage >= filter.MaxPages {
+			break
+		}
+
+		// Update the page number to get the next page.
+		options.PageNumber = rl.NextPage
+	}
+
+	return result, nil
+}
+
+var runsDescriptions = map[string]string{
+	"organization":     "The name of the Terraform Cloud organization that owns the workspace.",
+	"workspace":        "The name of the workspace to list the runs of.",
+	"status":           "Only list runs with this status, such as `applied`.",
+	"is_destroy":       "Only list destroy runs if true, or only non-destroy runs if false.",
+	"source":           "Only list runs from this source, such as `tfe-api` or `tfe-run-trigger`.",
+	"message_prefix":   "Only list runs whose message starts with this prefix.",
+	"message_contains": "Only list runs whose message contains this string.",
+	"created_after": "Only list runs created after this time, in RFC 3339 " +
+		"format. Setting this avoids reading older pages of runs.",
+	"created_before": "Only list runs created before this time, in RFC 3339 format.",
+	"limit": "The maximum number of runs to list, newest first. If zero, " +
+		"every matching run is listed.",
+	"runs":              "The matching runs, newest first.",
+	"id":                "The ID of the run.",
+	"runs.status":       "The status of the run.",
+	"runs.is_destroy":   "Whether the run is a destroy.",
+	"runs.source":       "The source of the run.",
+	"message":           "The message of the run.",
+	"has_changes":       "Whether the plan of the run has changes.",
+	"created_at":        "The time the run was created, in RFC 3339 format.",
+	"status_timestamps": "The time the run entered each status it has been in, keyed by status, in RFC 3339 format.",
+}
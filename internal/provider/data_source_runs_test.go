@@ -0,0 +1,38 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccDataSourceRuns(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceRuns,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.multispace_runs.root", "runs.#", "1"),
+					resource.TestCheckResourceAttr("data.multispace_runs.root", "runs.0.status", "applied"),
+				),
+			},
+		},
+	})
+}
+
+const testAccDataSourceRuns = `
+resource "multispace_run" "root" {
+  organization = "multispace-test"
+  workspace    = "root"
+}
+
+data "multispace_runs" "root" {
+  organization   = "multispace-test"
+  workspace      = multispace_run.root.workspace
+  status         = "applied"
+  message_prefix = "terraform-provider-multispace"
+  limit          = 1
+}
+`
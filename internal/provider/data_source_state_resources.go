@@ -0,0 +1,182 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceStateResources() *schema.Resource {
+	return &schema.Resource{
+		Description: "Resources in the current state of a workspace",
+
+		ReadContext: dataSourceStateResourcesRead,
+
+		Schema: map[string]*schema.Schema{
+			"organization": {
+				Description: stateResourcesDescriptions["organization"],
+				Type:        schema.TypeString,
+				Required:    true,
+			},
+
+			"workspace": {
+				Description: stateResourcesDescriptions["workspace"],
+				Type:        schema.TypeString,
+				Required:    true,
+			},
+
+			"type": {
+				Description: stateResourcesDescriptions["type"],
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+
+			"include_data_sources": {
+				Description: stateResourcesDescriptions["include_data_sources"],
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
+
+			"state_version_id": {
+				Description: stateResourcesDescriptions["state_version_id"],
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+
+			"resources": {
+				Description: stateResourcesDescriptions["resources"],
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"address": {
+							Description: stateResourcesDescriptions["address"],
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+
+						"module": {
+							Description: stateResourcesDescriptions["module"],
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+
+						"mode": {
+							Description: stateResourcesDescriptions["mode"],
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+
+						"type": {
+							Description: stateResourcesDescriptions["resources.type"],
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+
+						"name": {
+							Description: stateResourcesDescriptions["name"],
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+
+						"provider": {
+							Description: stateResourcesDescriptions["provider"],
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+
+						"instances": {
+							Description: stateResourcesDescriptions["instances"],
+							Type:        schema.TypeInt,
+							Computed:    true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceStateResourcesRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*providerMeta).client
+	org := d.Get("organization").(string)
+	workspace := d.Get("workspace").(string)
+
+	ws, err := client.Workspaces.Read(ctx, org, workspace)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	sv, err := currentStateVersion(ctx, client, ws.ID)
+	if err != nil {
+		return diag.Errorf("Failed to retrieve current state version: %s", err)
+	}
+
+	// A workspace without state has no resources.
+	resources := []interface{}{}
+	svID := ""
+	if sv != nil {
+		svID = sv.ID
+		state, err := readStateFile(ctx, client, sv)
+		if err != nil {
+			return diag.Errorf("Failed to read state of workspace %q: %s", workspace, err)
+		}
+
+		list := state.managedResources()
+		if d.Get("include_data_sources").(bool) {
+			list = state.Resources
+		}
+
+		typ := d.Get("type").(string)
+		for _, r := range list {
+			if typ != "" && r.Type != typ {
+				continue
+			}
+
+			resources = append(resources, map[string]interface{}{
+				"address":   r.address(),
+				"module":    r.Module,
+				"mode":      r.Mode,
+				"type":      r.Type,
+				"name":      r.Name,
+				"provider":  r.Provider,
+				"instances": len(r.Instances),
+			})
+		}
+	}
+
+	d.SetId(ws.ID)
+	if err := d.Set("state_version_id", svID); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("resources", resources); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
+
+var stateResourcesDescriptions = map[string]string{
+	"organization": "The name of the Terraform Cloud organization that owns the workspace.",
+	"workspace":    "The name of the workspace to read the state of.",
+	"type":         "Only list resources of this type, such as `aws_instance`.",
+	"include_data_sources": "If true, data sources in the state are listed " +
+		"as well as managed resources.",
+	"state_version_id": "The ID of the current state version. This is empty " +
+		"if the workspace has no state.",
+	"resources": "The resources in the current state. Managed resources " +
+		"without instances, which remain in the state after a destroy, are " +
+		"not listed, so this is empty once everything was destroyed.",
+	"address": "The address of the resource, such as " +
+		"`module.vpc.aws_vpc.this`. Instance keys are not included.",
+	"module":         "The address of the module of the resource, or empty for the root module.",
+	"mode":           "Either `managed` or `data`.",
+	"resources.type": "The type of the resource.",
+	"name":           "The name of the resource.",
+	"provider": "The provider configuration of the resource, such as " +
+		"`provider[\"registry.terraform.io/hashicorp/aws\"]`.",
+	"instances": "The number of instances of the resource, such as with " +
+		"`count` or `for_each`.",
+}
@@ -0,0 +1,35 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccDataSourceStateResources(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceStateResources,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet("data.multispace_state_resources.root", "state_version_id"),
+					resource.TestCheckResourceAttrSet("data.multispace_state_resources.root", "resources.#"),
+				),
+			},
+		},
+	})
+}
+
+const testAccDataSourceStateResources = `
+resource "multispace_run" "root" {
+  organization = "multispace-test"
+  workspace    = "root"
+}
+
+data "multispace_state_resources" "root" {
+  organization = "multispace-test"
+  workspace    = multispace_run.root.workspace
+}
+`
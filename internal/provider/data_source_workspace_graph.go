@@ -0,0 +1,203 @@
+package provider
+
+import (
+	"context"
+	"strings"
+
+	tfe "github.com/hashicorp/go-tfe"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceWorkspaceGraph() *schema.Resource {
+	return &schema.Resource{
+		Description: "Workspace dependency graph from run triggers",
+
+		ReadContext: dataSourceWorkspaceGraphRead,
+
+		Schema: map[string]*schema.Schema{
+			"organization": {
+				Description: workspaceGraphDescriptions["organization"],
+				Type:        schema.TypeString,
+				Required:    true,
+			},
+
+			"root_workspace": {
+				Description:   workspaceGraphDescriptions["root_workspace"],
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"tags"},
+			},
+
+			"tags": {
+				Description:   workspaceGraphDescriptions["tags"],
+				Type:          schema.TypeList,
+				Optional:      true,
+				Elem:          &schema.Schema{Type: schema.TypeString},
+				ConflictsWith: []string{"root_workspace"},
+			},
+
+			"fail_on_cycle": {
+				Description: workspaceGraphDescriptions["fail_on_cycle"],
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
+
+			"nodes": {
+				Description: workspaceGraphDescriptions["nodes"],
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+
+			"edges": {
+				Description: workspaceGraphDescriptions["edges"],
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"from": {
+							Description: workspaceGraphDescriptions["edges.from"],
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+
+						"to": {
+							Description: workspaceGraphDescriptions["edges.to"],
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+					},
+				},
+			},
+
+			"order": {
+				Description: workspaceGraphDescriptions["order"],
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+
+			"cycles": {
+				Description: workspaceGraphDescriptions["cycles"],
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"workspaces": {
+							Description: workspaceGraphDescriptions["cycles.workspaces"],
+							Type:        schema.TypeList,
+							Computed:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceWorkspaceGraphRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*providerMeta).client
+	org := d.Get("organization").(string)
+	id := org
+
+	var graph *workspaceGraph
+	if root := d.Get("root_workspace").(string); root != "" {
+		var err error
+		graph, err = discoverWorkspaceGraph(ctx, client, org, root)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		id = org + "/" + root
+	} else {
+		// Without a root we build the graph of every workspace in the
+		// organization, or every workspace with the tags.
+		options := tfe.WorkspaceListOptions{}
+		var tags []string
+		for _, raw := range d.Get("tags").([]interface{}) {
+			tags = append(tags, raw.(string))
+		}
+		if len(tags) > 0 {
+			options.Tags = tfe.String(strings.Join(tags, ","))
+			id = org + "/" + strings.Join(tags, ",")
+		}
+
+		workspaces, err := listWorkspaces(ctx, client, org, options)
+		if err != nil {
+			return diag.Errorf("Failed to retrieve workspaces: %s", err)
+		}
+
+		graph, err = buildWorkspaceGraph(ctx, client, workspaces)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	cycles := graph.Cycles()
+	if len(cycles) > 0 && d.Get("fail_on_cycle").(bool) {
+		// Levels returns the error describing the cycles.
+		_, err := graph.Levels()
+		return diag.FromErr(err)
+	}
+
+	// There is no order if the graph has cycles.
+	order := []string{}
+	if len(cycles) == 0 {
+		var err error
+		if order, err = graph.Order(); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	edges := []interface{}{}
+	for _, e := range graph.Edges() {
+		edges = append(edges, map[string]interface{}{
+			"from": e[0],
+			"to":   e[1],
+		})
+	}
+
+	flatCycles := []interface{}{}
+	for _, c := range cycles {
+		flatCycles = append(flatCycles, map[string]interface{}{
+			"workspaces": c,
+		})
+	}
+
+	d.SetId(id)
+	if err := d.Set("nodes", graph.Nodes()); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("edges", edges); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("order", order); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("cycles", flatCycles); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
+
+var workspaceGraphDescriptions = map[string]string{
+	"organization": "The name of the Terraform Cloud organization that owns the workspaces.",
+	"root_workspace": "Only include the workspaces reachable from this workspace " +
+		"through run triggers.",
+	"tags": "Only include the workspaces that have all of these tags. Run " +
+		"triggers to workspaces without the tags are ignored.",
+	"fail_on_cycle": "If true, reading the data source fails if the run " +
+		"triggers contain a cycle.",
+	"nodes": "The names of all the workspaces in the graph, sorted.",
+	"edges": "The run triggers between the workspaces, sorted. An edge from " +
+		"`from` to `to` means that an apply in `from` triggers a run in `to`.",
+	"edges.from": "The name of the workspace that triggers the run.",
+	"edges.to":   "The name of the workspace the run is triggered in.",
+	"order": "The workspaces in an order where every workspace comes after " +
+		"the workspaces that trigger it. This is empty if the graph has cycles.",
+	"cycles":            "The cycles in the graph.",
+	"cycles.workspaces": "The names of the workspaces in the cycle, sorted.",
+}
@@ -0,0 +1,31 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccDataSourceWorkspaceGraph(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceWorkspaceGraph,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.multispace_workspace_graph.root", "order.0", "root"),
+					resource.TestCheckResourceAttr("data.multispace_workspace_graph.root", "cycles.#", "0"),
+				),
+			},
+		},
+	})
+}
+
+const testAccDataSourceWorkspaceGraph = `
+data "multispace_workspace_graph" "root" {
+  organization   = "multispace-test"
+  root_workspace = "root"
+  fail_on_cycle  = true
+}
+`
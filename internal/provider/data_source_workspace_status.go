@@ -0,0 +1,295 @@
+package provider
+
+import (
+	"context"
+	"time"
+
+	tfe "github.com/hashicorp/go-tfe"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceWorkspaceStatus() *schema.Resource {
+	s := map[string]*schema.Schema{
+		"organization": {
+			Description: workspaceStatusDescriptions["organization"],
+			Type:        schema.TypeString,
+			Required:    true,
+		},
+
+		"all_ready": {
+			Description: workspaceStatusDescriptions["all_ready"],
+			Type:        schema.TypeBool,
+			Computed:    true,
+		},
+
+		"statuses": {
+			Description: workspaceStatusDescriptions["statuses"],
+			Type:        schema.TypeList,
+			Computed:    true,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"workspace": {
+						Description: workspaceStatusDescriptions["workspace"],
+						Type:        schema.TypeString,
+						Computed:    true,
+					},
+
+					"workspace_id": {
+						Description: workspaceStatusDescriptions["workspace_id"],
+						Type:        schema.TypeString,
+						Computed:    true,
+					},
+
+					"locked": {
+						Description: workspaceStatusDescriptions["locked"],
+						Type:        schema.TypeBool,
+						Computed:    true,
+					},
+
+					"locked_by_provider": {
+						Description: workspaceStatusDescriptions["locked_by_provider"],
+						Type:        schema.TypeBool,
+						Computed:    true,
+					},
+
+					"locked_by_run_id": {
+						Description: workspaceStatusDescriptions["locked_by_run_id"],
+						Type:        schema.TypeString,
+						Computed:    true,
+					},
+
+					"current_run_id": {
+						Description: workspaceStatusDescriptions["current_run_id"],
+						Type:        schema.TypeString,
+						Computed:    true,
+					},
+
+					"current_run_status": {
+						Description: workspaceStatusDescriptions["current_run_status"],
+						Type:        schema.TypeString,
+						Computed:    true,
+					},
+
+					"last_applied_run_id": {
+						Description: workspaceStatusDescriptions["last_applied_run_id"],
+						Type:        schema.TypeString,
+						Computed:    true,
+					},
+
+					"last_applied_at": {
+						Description: workspaceStatusDescriptions["last_applied_at"],
+						Type:        schema.TypeString,
+						Computed:    true,
+					},
+
+					"seconds_since_last_apply": {
+						Description: workspaceStatusDescriptions["seconds_since_last_apply"],
+						Type:        schema.TypeInt,
+						Computed:    true,
+					},
+
+					"pending_runs": {
+						Description: workspaceStatusDescriptions["pending_runs"],
+						Type:        schema.TypeInt,
+						Computed:    true,
+					},
+
+					"ready": {
+						Description: workspaceStatusDescriptions["ready"],
+						Type:        schema.TypeBool,
+						Computed:    true,
+					},
+				},
+			},
+		},
+	}
+	addWorkspaceSelectorSchema(s)
+
+	return &schema.Resource{
+		Description: "Lock and run status of many workspaces",
+
+		ReadContext: dataSourceWorkspaceStatusRead,
+
+		Schema: s,
+	}
+}
+
+func dataSourceWorkspaceStatusRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*providerMeta).client
+	org := d.Get("organization").(string)
+
+	workspaces, err := selectWorkspaces(ctx, client, org, d)
+	if err != nil {
+		return diag.Errorf("Failed to retrieve workspaces: %s", err)
+	}
+
+	now := time.Now()
+	allReady := true
+	statuses := make([]interface{}, 0, len(workspaces))
+	for _, ws := range workspaces {
+		s, err := readWorkspaceStatus(ctx, client, ws)
+		if err != nil {
+			return diag.Errorf(
+				"Failed to retrieve status of workspace %q: %s", ws.Name, err)
+		}
+
+		ready := s.ready()
+		allReady = allReady && ready
+
+		m := map[string]interface{}{
+			"workspace":                ws.Name,
+			"workspace_id":             ws.ID,
+			"locked":                   s.Locked,
+			"locked_by_provider":       s.LockedByProvider,
+			"locked_by_run_id":         "",
+			"current_run_id":           "",
+			"current_run_status":       "",
+			"last_applied_run_id":      "",
+			"last_applied_at":          "",
+			"seconds_since_last_apply": -1,
+			"pending_runs":             s.PendingRuns,
+			"ready":                    ready,
+		}
+		if s.CurrentRun != nil {
+			m["current_run_id"] = s.CurrentRun.ID
+			m["current_run_status"] = string(s.CurrentRun.Status)
+			if s.Locked && !runFinished(s.CurrentRun.Status) {
+				m["locked_by_run_id"] = s.CurrentRun.ID
+			}
+		}
+		if s.LastApplied != nil {
+			m["last_applied_run_id"] = s.LastApplied.ID
+			if ts := s.LastApplied.StatusTimestamps; ts != nil && !ts.AppliedAt.IsZero() {
+				m["last_applied_at"] = ts.AppliedAt.Format(time.RFC3339)
+				m["seconds_since_last_apply"] = int(now.Sub(ts.AppliedAt).Seconds())
+			}
+		}
+
+		statuses = append(statuses, m)
+	}
+
+	d.SetId(org)
+	if err := d.Set("all_ready", allReady); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("statuses", statuses); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
+
+// workspaceStatus is the state of a workspace that matters before we
+// queue runs in it.
+type workspaceStatus struct {
+	Locked           bool
+	LockedByProvider bool
+	CurrentRun       *tfe.Run
+	LastApplied      *tfe.Run
+	PendingRuns      int
+}
+
+// readWorkspaceStatus reads the current run of the workspace and pages
+// through its runs until the last applied run, counting the pending runs.
+func readWorkspaceStatus(
+	ctx context.Context,
+	client *tfe.Client,
+	ws *tfe.Workspace,
+) (*workspaceStatus, error) {
+	s := &workspaceStatus{Locked: ws.Locked}
+	if ws.Locked {
+		_, s.LockedByProvider = heldWorkspaceLock(ws.ID)
+	}
+
+	if ws.CurrentRun != nil {
+		cr, err := client.Runs.Read(ctx, ws.CurrentRun.ID)
+		if err != nil {
+			return nil, err
+		}
+		s.CurrentRun = cr
+	}
+
+	lastApplied, err := findLatestRun(ctx, client, ws.ID, runFilter{
+		Status: string(tfe.RunApplied),
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.LastApplied = lastApplied
+
+	// Runs are queued in order, so no run older than the last applied run
+	// is still pending and we can stop listing there.
+	filter := runFilter{Status: string(tfe.RunPending)}
+	if lastApplied != nil {
+		filter.CreatedAfter = lastApplied.CreatedAt
+	}
+	pending, err := listRuns(ctx, client, ws.ID, filter, 0)
+	if err != nil {
+		return nil, err
+	}
+	s.PendingRuns = len(pending)
+
+	return s, nil
+}
+
+// ready returns true if a run we queue in the workspace would start right
+// away: the workspace isn't locked by anyone else, its current run is done
+// without an error, and no other runs are waiting.
+func (s *workspaceStatus) ready() bool {
+	// A run in progress holds the lock and is covered below, so a lock
+	// without one is held by a user or team.
+	if s.Locked && !s.LockedByProvider && (s.CurrentRun == nil || runFinished(s.CurrentRun.Status)) {
+		return false
+	}
+
+	if s.CurrentRun != nil {
+		if !runFinished(s.CurrentRun.Status) || s.CurrentRun.Status == tfe.RunErrored {
+			return false
+		}
+	}
+
+	return s.PendingRuns == 0
+}
+
+// runFinished returns true if the run is in a final state. A run that
+// planned and is waiting for confirmation is not finished.
+func runFinished(status tfe.RunStatus) bool {
+	switch status {
+	case tfe.RunApplied,
+		tfe.RunCanceled,
+		tfe.RunDiscarded,
+		tfe.RunErrored,
+		tfe.RunPlannedAndFinished:
+		return true
+	}
+
+	return false
+}
+
+var workspaceStatusDescriptions = map[string]string{
+	"organization": "The name of the Terraform Cloud organization that owns the workspaces.",
+	"all_ready":    "Whether every selected workspace is `ready`.",
+	"statuses":     "The status of each selected workspace, sorted by name.",
+	"workspace":    "The name of the workspace.",
+	"workspace_id": "The ID of the workspace.",
+	"locked": "Whether the workspace is locked, either by a user, a team, " +
+		"a run in progress, or a `multispace_workspace_lock`.",
+	"locked_by_provider": "Whether the workspace is locked by a " +
+		"`multispace_workspace_lock` in this configuration. Runs queued by " +
+		"this provider go through this lock.",
+	"locked_by_run_id": "The ID of the run holding the lock, if the lock " +
+		"is held by a run in progress.",
+	"current_run_id":     "The ID of the current run of the workspace, if any.",
+	"current_run_status": "The status of the current run, such as `planned` or `errored`.",
+	"last_applied_run_id": "The ID of the latest applied run, or empty if " +
+		"the workspace was never applied.",
+	"last_applied_at": "The time of the latest apply, in RFC 3339 format.",
+	"seconds_since_last_apply": "The number of seconds since the latest " +
+		"apply, or -1 if the workspace was never applied.",
+	"pending_runs": "The number of runs waiting for the current run to finish.",
+	"ready": "Whether a run queued now would start right away: the workspace " +
+		"isn't locked other than by this provider, the current run finished " +
+		"without an error or a plan waiting for confirmation, and no runs " +
+		"are pending.",
+}
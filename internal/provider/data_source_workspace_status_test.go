@@ -0,0 +1,62 @@
+package provider
+
+import (
+	"testing"
+
+	tfe "github.com/hashicorp/go-tfe"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestWorkspaceStatusReady(t *testing.T) {
+	run := func(status tfe.RunStatus) *tfe.Run {
+		return &tfe.Run{ID: "run-abc", Status: status}
+	}
+
+	cases := []struct {
+		Name     string
+		Status   workspaceStatus
+		Expected bool
+	}{
+		{"new workspace", workspaceStatus{}, true},
+		{"applied", workspaceStatus{CurrentRun: run(tfe.RunApplied)}, true},
+		{"planned and finished", workspaceStatus{CurrentRun: run(tfe.RunPlannedAndFinished)}, true},
+		{"discarded", workspaceStatus{CurrentRun: run(tfe.RunDiscarded)}, true},
+		{"errored", workspaceStatus{CurrentRun: run(tfe.RunErrored)}, false},
+		{"unconfirmed plan", workspaceStatus{Locked: true, CurrentRun: run(tfe.RunPlanned)}, false},
+		{"applying", workspaceStatus{Locked: true, CurrentRun: run(tfe.RunApplying)}, false},
+		{"locked by user", workspaceStatus{Locked: true, CurrentRun: run(tfe.RunApplied)}, false},
+		{"locked by provider", workspaceStatus{Locked: true, LockedByProvider: true, CurrentRun: run(tfe.RunApplied)}, true},
+		{"pending runs", workspaceStatus{CurrentRun: run(tfe.RunApplied), PendingRuns: 2}, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			if actual := tc.Status.ready(); actual != tc.Expected {
+				t.Fatalf("expected %v, got %v", tc.Expected, actual)
+			}
+		})
+	}
+}
+
+func TestAccDataSourceWorkspaceStatus(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceWorkspaceStatus,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.multispace_workspace_status.all", "statuses.#", "4"),
+					resource.TestCheckResourceAttr("data.multispace_workspace_status.all", "statuses.0.workspace", "A"),
+				),
+			},
+		},
+	})
+}
+
+const testAccDataSourceWorkspaceStatus = `
+data "multispace_workspace_status" "all" {
+  organization = "multispace-test"
+  workspaces   = ["root", "A", "B", "C"]
+}
+`
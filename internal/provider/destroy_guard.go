@@ -0,0 +1,231 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tfe "github.com/hashicorp/go-tfe"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// destroyGuard refuses destroy runs unless the workspace explicitly allows
+// them with a tag or a variable. If both are set, either one allows the
+// destroy.
+type destroyGuard struct {
+	WorkspaceTag  string
+	VariableKey   string
+	VariableValue string
+}
+
+// destroyGuardSchema is the schema for the prevent_destroy_unless block.
+func destroyGuardSchema() *schema.Schema {
+	return &schema.Schema{
+		Description: destroyGuardDescriptions["prevent_destroy_unless"],
+		Type:        schema.TypeList,
+		Optional:    true,
+		MaxItems:    1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"workspace_tag": {
+					Description: destroyGuardDescriptions["workspace_tag"],
+					Type:        schema.TypeString,
+					Optional:    true,
+				},
+
+				"variable": {
+					Description: destroyGuardDescriptions["variable"],
+					Type:        schema.TypeList,
+					Optional:    true,
+					MaxItems:    1,
+					Elem: &schema.Resource{
+						Schema: map[string]*schema.Schema{
+							"key": {
+								Description: destroyGuardDescriptions["variable.key"],
+								Type:        schema.TypeString,
+								Required:    true,
+							},
+
+							"value": {
+								Description: destroyGuardDescriptions["variable.value"],
+								Type:        schema.TypeString,
+								Optional:    true,
+								Default:     "true",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// readDestroyGuard reads the prevent_destroy_unless block, returning nil
+// if it isn't set.
+func readDestroyGuard(d *schema.ResourceData) *destroyGuard {
+	if _, ok := d.GetOk("prevent_destroy_unless"); !ok {
+		return nil
+	}
+
+	g := &destroyGuard{
+		WorkspaceTag: d.Get("prevent_destroy_unless.0.workspace_tag").(string),
+	}
+	if _, ok := d.GetOk("prevent_destroy_unless.0.variable"); ok {
+		g.VariableKey = d.Get("prevent_destroy_unless.0.variable.0.key").(string)
+		g.VariableValue = d.Get("prevent_destroy_unless.0.variable.0.value").(string)
+	}
+
+	return g
+}
+
+// allowed returns true if the workspace tags or variables allow a destroy.
+// A guard with no conditions never allows a destroy.
+func (g *destroyGuard) allowed(tags []string, vars []*tfe.Variable) bool {
+	if g.WorkspaceTag != "" {
+		for _, t := range tags {
+			if strings.EqualFold(t, g.WorkspaceTag) {
+				return true
+			}
+		}
+	}
+
+	if g.VariableKey != "" {
+		for _, v := range vars {
+			// Sensitive values are never returned by the API so they can't
+			// be used to allow a destroy.
+			if v.Key == g.VariableKey && !v.Sensitive && v.Value == g.VariableValue {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// checkDestroyGuards checks the guard of every workspace before any of
+// them is destroyed, so that a workspace that refuses the destroy doesn't
+// leave the others half destroyed. Every refusal is returned.
+func checkDestroyGuards(
+	ctx context.Context,
+	client *tfe.Client,
+	allowDestroy bool,
+	g *destroyGuard,
+	org string,
+	workspaces []string,
+) diag.Diagnostics {
+	var diags diag.Diagnostics
+	for _, workspace := range workspaces {
+		diags = append(diags, checkDestroyGuard(ctx, client, allowDestroy, g, org, workspace)...)
+	}
+
+	return diags
+}
+
+// checkDestroyGuard returns an error if destroys aren't allowed by the
+// provider or if the guard refuses a destroy of the workspace. A nil guard
+// allows every destroy.
+func checkDestroyGuard(
+	ctx context.Context,
+	client *tfe.Client,
+	allowDestroy bool,
+	g *destroyGuard,
+	org string,
+	workspace string,
+) diag.Diagnostics {
+	if !allowDestroy {
+		return diag.Diagnostics{{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("Destroy of workspace %q prevented", workspace),
+			Detail: "The destroy run was not queued because the provider is " +
+				"configured with allow_destroy = false. To allow it, set " +
+				"allow_destroy to true, then destroy again.",
+		}}
+	}
+
+	if g == nil {
+		return nil
+	}
+
+	ws, err := client.Workspaces.Read(ctx, org, workspace)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	var vars []*tfe.Variable
+	if g.VariableKey != "" {
+		vars, err = listVariables(ctx, client, ws.ID)
+		if err != nil {
+			return diag.Errorf("Failed to retrieve workspace variables: %s", err)
+		}
+	}
+
+	if g.allowed(ws.TagNames, vars) {
+		logger.Named("run").Info("destroy allowed by prevent_destroy_unless",
+			"organization", org,
+			"workspace", workspace,
+		)
+		return nil
+	}
+
+	var how []string
+	if g.WorkspaceTag != "" {
+		how = append(how, fmt.Sprintf("add the tag %q to the workspace", g.WorkspaceTag))
+	}
+	if g.VariableKey != "" {
+		how = append(how, fmt.Sprintf(
+			"set the non-sensitive workspace variable %q to %q",
+			g.VariableKey, g.VariableValue))
+	}
+	if len(how) == 0 {
+		how = append(how, "remove the prevent_destroy_unless block and apply")
+	}
+
+	return diag.Diagnostics{{
+		Severity: diag.Error,
+		Summary:  fmt.Sprintf("Destroy of workspace %q prevented", workspace),
+		Detail: fmt.Sprintf(
+			"The destroy run was not queued because of prevent_destroy_unless. "+
+				"To allow it, %s, then destroy again.",
+			strings.Join(how, " or ")),
+	}}
+}
+
+// listVariables returns all the variables of a workspace, reading every page.
+func listVariables(
+	ctx context.Context,
+	client *tfe.Client,
+	workspaceID string,
+) ([]*tfe.Variable, error) {
+	var result []*tfe.Variable
+	options := tfe.VariableListOptions{}
+	for {
+		vl, err := client.Variables.List(ctx, workspaceID, options)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, vl.Items...)
+
+		// Exit the loop when we've seen all pages.
+		if vl.Pagination == nil || vl.CurrentPage >= vl.TotalPages {
+			break
+		}
+
+		// Update the page number to get the next page.
+		options.PageNumber = vl.NextPage
+	}
+
+	return result, nil
+}
+
+var destroyGuardDescriptions = map[string]string{
+	"prevent_destroy_unless": "If set, destroy runs are refused unless the " +
+		"workspace allows them with the tag or variable configured here. " +
+		"If both are configured, either one allows the destroy. An empty " +
+		"block refuses every destroy.",
+	"workspace_tag": "Allow destroys if the workspace has this tag.",
+	"variable": "Allow destroys if the workspace has a non-sensitive variable " +
+		"with this key and value.",
+	"variable.key":   "The key of the variable.",
+	"variable.value": "The value the variable must have.",
+}
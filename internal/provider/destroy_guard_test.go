@@ -0,0 +1,57 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	tfe "github.com/hashicorp/go-tfe"
+)
+
+func TestDestroyGuardAllowed(t *testing.T) {
+	vars := []*tfe.Variable{
+		{Key: "allow_destroy", Value: "true"},
+		{Key: "secret", Value: "", Sensitive: true},
+	}
+
+	cases := []struct {
+		Name     string
+		Guard    destroyGuard
+		Tags     []string
+		Vars     []*tfe.Variable
+		Expected bool
+	}{
+		{"empty", destroyGuard{}, []string{"allow-destroy"}, vars, false},
+		{"tag", destroyGuard{WorkspaceTag: "allow-destroy"}, []string{"prod", "Allow-Destroy"}, nil, true},
+		{"tag missing", destroyGuard{WorkspaceTag: "allow-destroy"}, []string{"prod"}, vars, false},
+		{"variable", destroyGuard{VariableKey: "allow_destroy", VariableValue: "true"}, nil, vars, true},
+		{"variable value", destroyGuard{VariableKey: "allow_destroy", VariableValue: "yes"}, nil, vars, false},
+		{"variable sensitive", destroyGuard{VariableKey: "secret", VariableValue: ""}, nil, vars, false},
+		{
+			"either",
+			destroyGuard{WorkspaceTag: "allow-destroy", VariableKey: "allow_destroy", VariableValue: "true"},
+			nil, vars, true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			if actual := tc.Guard.allowed(tc.Tags, tc.Vars); actual != tc.Expected {
+				t.Fatalf("expected %v, got %v", tc.Expected, actual)
+			}
+		})
+	}
+}
+
+func TestDestroyGuardProviderDisallowed(t *testing.T) {
+	client := &tfe.Client{}
+
+	// Without a guard, destroys are allowed without reading the workspace.
+	if diags := checkDestroyGuards(context.Background(), client, true, nil, "org", []string{"A", "B"}); diags.HasError() {
+		t.Fatalf("unexpected error: %#v", diags)
+	}
+
+	diags := checkDestroyGuards(context.Background(), client, false, nil, "org", []string{"A", "B"})
+	if len(diags) != 2 || !diags.HasError() {
+		t.Fatalf("expected an error per workspace: %#v", diags)
+	}
+}
@@ -0,0 +1,307 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	tfe "github.com/hashicorp/go-tfe"
+)
+
+// workspaceGraph is a graph of workspaces connected by run triggers. An
+// edge from A to B means that an apply in A triggers a run in B, so B
+// depends on A.
+type workspaceGraph struct {
+	nodes map[string]struct{}
+	edges map[string]map[string]struct{}
+}
+
+func newWorkspaceGraph() *workspaceGraph {
+	return &workspaceGraph{
+		nodes: map[string]struct{}{},
+		edges: map[string]map[string]struct{}{},
+	}
+}
+
+func (g *workspaceGraph) addNode(n string) {
+	g.nodes[n] = struct{}{}
+}
+
+// addEdge adds an edge from the source workspace to the workspace it
+// triggers, adding both as nodes if necessary.
+func (g *workspaceGraph) addEdge(from, to string) {
+	g.addNode(from)
+	g.addNode(to)
+	if g.edges[from] == nil {
+		g.edges[from] = map[string]struct{}{}
+	}
+	g.edges[from][to] = struct{}{}
+}
+
+// Nodes returns the names of all workspaces in the graph, sorted.
+func (g *workspaceGraph) Nodes() []string {
+	result := make([]string, 0, len(g.nodes))
+	for n := range g.nodes {
+		result = append(result, n)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// Targets returns the workspaces triggered by the given workspace, sorted.
+func (g *workspaceGraph) Targets(n string) []string {
+	result := make([]string, 0, len(g.edges[n]))
+	for t := range g.edges[n] {
+		result = append(result, t)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// Edges returns every edge in the graph as a pair of source and target
+// workspace, sorted.
+func (g *workspaceGraph) Edges() [][2]string {
+	var result [][2]string
+	for _, n := range g.Nodes() {
+		for _, t := range g.Targets(n) {
+			result = append(result, [2]string{n, t})
+		}
+	}
+	return result
+}
+
+// Levels groups the workspaces so that every workspace comes after all
+// of the workspaces that trigger it. Workspaces within a level don't
+// depend on each other. This returns an error if the graph has a cycle.
+func (g *workspaceGraph) Levels() ([][]string, error) {
+	if cycles := g.Cycles(); len(cycles) > 0 {
+		var parts []string
+		for _, c := range cycles {
+			parts = append(parts, strings.Join(c, ", "))
+		}
+		return nil, fmt.Errorf(
+			"run triggers contain a cycle between workspaces: %s",
+			strings.Join(parts, "; "))
+	}
+
+	inDegree := map[string]int{}
+	for n := range g.nodes {
+		for t := range g.edges[n] {
+			inDegree[t]++
+		}
+	}
+
+	var result [][]string
+	var current []string
+	for _, n := range g.Nodes() {
+		if inDegree[n] == 0 {
+			current = append(current, n)
+		}
+	}
+	for len(current) > 0 {
+		result = append(result, current)
+
+		var next []string
+		for _, n := range current {
+			for _, t := range g.Targets(n) {
+				inDegree[t]--
+				if inDegree[t] == 0 {
+					next = append(next, t)
+				}
+			}
+		}
+		sort.Strings(next)
+		current = next
+	}
+
+	return result, nil
+}
+
+// Order returns the workspaces in a topological order, which is the
+// levels flattened.
+func (g *workspaceGraph) Order() ([]string, error) {
+	levels, err := g.Levels()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []string
+	for _, l := range levels {
+		result = append(result, l...)
+	}
+	return result, nil
+}
+
+// Cycles returns every cycle in the graph. Each cycle is the sorted list
+// of workspaces in it. This uses Tarjan's strongly connected components
+// algorithm, so workspaces that are part of overlapping cycles are
+// reported as a single cycle.
+func (g *workspaceGraph) Cycles() [][]string {
+	index := 0
+	indices := map[string]int{}
+	lowlink := map[string]int{}
+	onStack := map[string]bool{}
+	var stack []string
+	var result [][]string
+
+	var visit func(n string)
+	visit = func(n string) {
+		indices[n] = index
+		lowlink[n] = index
+		index++
+		stack = append(stack, n)
+		onStack[n] = true
+
+		for _, t := range g.Targets(n) {
+			if _, ok := indices[t]; !ok {
+				visit(t)
+				if lowlink[t] < lowlink[n] {
+					lowlink[n] = lowlink[t]
+				}
+			} else if onStack[t] && indices[t] < lowlink[n] {
+				lowlink[n] = indices[t]
+			}
+		}
+
+		if lowlink[n] != indices[n] {
+			return
+		}
+
+		var scc []string
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[top] = false
+			scc = append(scc, top)
+			if top == n {
+				break
+			}
+		}
+
+		// A single node is only a cycle if it triggers itself.
+		if len(scc) == 1 {
+			if _, ok := g.edges[n][n]; !ok {
+				return
+			}
+		}
+
+		sort.Strings(scc)
+		result = append(result, scc)
+	}
+
+	for _, n := range g.Nodes() {
+		if _, ok := indices[n]; !ok {
+			visit(n)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i][0] < result[j][0]
+	})
+	return result
+}
+
+// discoverWorkspaceGraph builds the graph of all workspaces reachable
+// from the root workspace by following outbound run triggers.
+func discoverWorkspaceGraph(
+	ctx context.Context,
+	client *tfe.Client,
+	org string,
+	root string,
+) (*workspaceGraph, error) {
+	ws, err := client.Workspaces.Read(ctx, org, root)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to retrieve workspace %q: %s", root, err)
+	}
+
+	g := newWorkspaceGraph()
+	g.addNode(ws.Name)
+
+	queue := []*tfe.Workspace{ws}
+	seen := map[string]bool{ws.Name: true}
+	for len(queue) > 0 {
+		w := queue[0]
+		queue = queue[1:]
+
+		triggers, err := listRunTriggers(ctx, client, w.ID, "outbound")
+		if err != nil {
+			return nil, fmt.Errorf(
+				"Failed to retrieve run triggers for workspace %q: %s", w.Name, err)
+		}
+
+		for _, t := range triggers {
+			g.addEdge(w.Name, t.WorkspaceName)
+			if seen[t.WorkspaceName] || t.Workspace == nil {
+				continue
+			}
+
+			seen[t.WorkspaceName] = true
+			queue = append(queue, &tfe.Workspace{
+				ID:   t.Workspace.ID,
+				Name: t.WorkspaceName,
+			})
+		}
+	}
+
+	return g, nil
+}
+
+// buildWorkspaceGraph builds the graph of the given workspaces from their
+// outbound run triggers. Run triggers to workspaces that aren't in the list
+// are ignored.
+func buildWorkspaceGraph(
+	ctx context.Context,
+	client *tfe.Client,
+	workspaces []*tfe.Workspace,
+) (*workspaceGraph, error) {
+	g := newWorkspaceGraph()
+	for _, ws := range workspaces {
+		g.addNode(ws.Name)
+	}
+
+	for _, ws := range workspaces {
+		triggers, err := listRunTriggers(ctx, client, ws.ID, "outbound")
+		if err != nil {
+			return nil, fmt.Errorf(
+				"Failed to retrieve run triggers for workspace %q: %s", ws.Name, err)
+		}
+
+		for _, t := range triggers {
+			if _, ok := g.nodes[t.WorkspaceName]; ok {
+				g.addEdge(ws.Name, t.WorkspaceName)
+			}
+		}
+	}
+
+	return g, nil
+}
+
+// listRunTriggers returns all the run triggers of the given type
+// ("inbound" or "outbound") for a workspace, reading every page.
+func listRunTriggers(
+	ctx context.Context,
+	client *tfe.Client,
+	workspaceID string,
+	typ string,
+) ([]*tfe.RunTrigger, error) {
+	var result []*tfe.RunTrigger
+	options := tfe.RunTriggerListOptions{RunTriggerType: tfe.String(typ)}
+	for {
+		rtl, err := client.RunTriggers.List(ctx, workspaceID, options)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, rtl.Items...)
+
+		// Exit the loop when we've seen all pages.
+		if rtl.Pagination == nil || rtl.CurrentPage >= rtl.TotalPages {
+			break
+		}
+
+		// Update the page number to get the next page.
+		options.PageNumber = rtl.NextPage
+	}
+
+	return result, nil
+}
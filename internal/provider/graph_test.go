@@ -0,0 +1,72 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWorkspaceGraphLevels(t *testing.T) {
+	g := newWorkspaceGraph()
+	g.addEdge("root", "physical")
+	g.addEdge("root", "dns")
+	g.addEdge("physical", "core")
+	g.addEdge("core", "ingress")
+	g.addEdge("dns", "ingress")
+	g.addNode("standalone")
+
+	actual, err := g.Levels()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := [][]string{
+		{"root", "standalone"},
+		{"dns", "physical"},
+		{"core"},
+		{"ingress"},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("bad: %#v", actual)
+	}
+
+	order, err := g.Order()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	expectedOrder := []string{"root", "standalone", "dns", "physical", "core", "ingress"}
+	if !reflect.DeepEqual(order, expectedOrder) {
+		t.Fatalf("bad: %#v", order)
+	}
+
+	expectedEdges := [][2]string{
+		{"core", "ingress"},
+		{"dns", "ingress"},
+		{"physical", "core"},
+		{"root", "dns"},
+		{"root", "physical"},
+	}
+	if edges := g.Edges(); !reflect.DeepEqual(edges, expectedEdges) {
+		t.Fatalf("bad: %#v", edges)
+	}
+}
+
+func TestWorkspaceGraphCycles(t *testing.T) {
+	g := newWorkspaceGraph()
+	g.addEdge("root", "A")
+	g.addEdge("A", "B")
+	g.addEdge("B", "C")
+	g.addEdge("C", "A")
+	g.addEdge("D", "D")
+
+	expected := [][]string{
+		{"A", "B", "C"},
+		{"D"},
+	}
+	if actual := g.Cycles(); !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("bad: %#v", actual)
+	}
+
+	if _, err := g.Levels(); err == nil {
+		t.Fatal("expected error")
+	}
+}
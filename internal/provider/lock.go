@@ -0,0 +1,345 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	tfe "github.com/hashicorp/go-tfe"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+// heldLocks are the workspace locks held by multispace_workspace_lock
+// resources in this provider process, keyed by workspace ID with the
+// lock reason as the value. This is only a hint: it is empty in other
+// processes and with -refresh=false, so whether we hold a lock is always
+// decided by the lock holder that Terraform Cloud reports.
+var heldLocks = struct {
+	sync.Mutex
+	m map[string]string
+}{m: map[string]string{}}
+
+func holdWorkspaceLock(id, reason string) {
+	heldLocks.Lock()
+	defer heldLocks.Unlock()
+	heldLocks.m[id] = reason
+}
+
+func releaseWorkspaceLock(id string) {
+	heldLocks.Lock()
+	defer heldLocks.Unlock()
+	delete(heldLocks.m, id)
+}
+
+func heldWorkspaceLock(id string) (string, bool) {
+	heldLocks.Lock()
+	defer heldLocks.Unlock()
+	reason, ok := heldLocks.m[id]
+	return reason, ok
+}
+
+// workspaceLockHolder is the user, team, or run that holds the lock on a
+// workspace.
+type workspaceLockHolder struct {
+	// Type is "users", "teams", or "runs".
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+// readWorkspaceLockHolder returns the holder of the lock on the workspace,
+// or nil if it isn't locked. go-tfe doesn't expose the holder, so the API
+// is requested directly.
+func readWorkspaceLockHolder(
+	ctx context.Context,
+	api *apiClient,
+	workspaceID string,
+) (*workspaceLockHolder, error) {
+	var ws struct {
+		Data struct {
+			Attributes struct {
+				Locked bool `json:"locked"`
+			} `json:"attributes"`
+			Relationships struct {
+				LockedBy struct {
+					Data *workspaceLockHolder `json:"data"`
+				} `json:"locked-by"`
+			} `json:"relationships"`
+		} `json:"data"`
+	}
+	if err := api.get(ctx, "workspaces/"+url.PathEscape(workspaceID), nil, &ws); err != nil {
+		return nil, err
+	}
+	if !ws.Data.Attributes.Locked {
+		return nil, nil
+	}
+
+	holder := ws.Data.Relationships.LockedBy.Data
+	if holder == nil {
+		holder = &workspaceLockHolder{}
+	}
+
+	return holder, nil
+}
+
+// String describes the holder for error messages, such as `user "user-123"`.
+func (h *workspaceLockHolder) String() string {
+	if h == nil {
+		return "nobody"
+	}
+
+	kind := strings.TrimSuffix(h.Type, "s")
+	if kind == "" {
+		kind = "unknown holder"
+	}
+
+	return fmt.Sprintf("%s %q", kind, h.ID)
+}
+
+// lockHandoff hands a lock held by the user of this provider off to the
+// runs we queue in the workspace. A nil lockHandoff does nothing, so it can
+// be used whether or not we hold a lock.
+type lockHandoff struct {
+	client    *tfe.Client
+	api       *apiClient
+	org       string
+	workspace *tfe.Workspace
+	user      string
+	reason    string
+	logger    hclog.Logger
+
+	// locked is true while the workspace is locked by us.
+	locked bool
+
+	// handedOff is true once we unlocked the workspace for our run and
+	// have to take the lock back.
+	handedOff bool
+
+	// runID is the run the lock was handed off to.
+	runID string
+}
+
+// handoffWorkspaceLock returns a lockHandoff if the workspace is locked by
+// the user that this provider authenticates as, such as by a
+// multispace_workspace_lock, or nil if it isn't. The workspace stays
+// locked until our run is queued. Locks held by anyone else are left
+// alone and our run waits for them like any other run.
+func handoffWorkspaceLock(
+	ctx context.Context,
+	client *tfe.Client,
+	api *apiClient,
+	org string,
+	workspace string,
+) (*lockHandoff, diag.Diagnostics) {
+	if api == nil {
+		return nil, nil
+	}
+
+	ws, err := client.Workspaces.Read(ctx, org, workspace)
+	if err != nil {
+		return nil, diag.FromErr(err)
+	}
+	if !ws.Locked {
+		return nil, nil
+	}
+
+	holder, err := readWorkspaceLockHolder(ctx, api, ws.ID)
+	if err != nil {
+		return nil, diag.Errorf("Failed to retrieve the lock holder of workspace %q: %s", workspace, err)
+	}
+	user, err := client.Users.ReadCurrent(ctx)
+	if err != nil {
+		return nil, diag.Errorf("Failed to retrieve the current user: %s", err)
+	}
+
+	logger := logger.Named("lock").With(
+		"organization", org,
+		"workspace", workspace,
+	)
+	if holder == nil || holder.Type != "users" || holder.ID != user.ID {
+		logger.Info("workspace is locked by someone else, our run will wait for it",
+			"lock_holder", holder.String())
+		return nil, nil
+	}
+
+	// Locks taken by a multispace_workspace_lock in this process are taken
+	// back with their reason. The reason isn't available otherwise.
+	reason, ok := heldWorkspaceLock(ws.ID)
+	if !ok {
+		reason = defaultLockReason
+	}
+
+	return &lockHandoff{
+		client:    client,
+		api:       api,
+		org:       org,
+		workspace: ws,
+		user:      user.ID,
+		reason:    reason,
+		logger:    logger,
+		locked:    true,
+	}, nil
+}
+
+// ours returns true if the lock is held by our user or by our run.
+func (h *lockHandoff) ours(holder *workspaceLockHolder) bool {
+	if holder == nil {
+		return false
+	}
+
+	return (holder.Type == "users" && holder.ID == h.user) ||
+		(holder.Type == "runs" && holder.ID == h.runID)
+}
+
+// queued hands the lock off to our run, which was queued while the
+// workspace was locked. If another run was queued ahead of ours, it would
+// start first once the workspace is unlocked, so we discard our own run and
+// fail instead. The lock is taken back as soon as our run starts.
+func (h *lockHandoff) queued(ctx context.Context, run *tfe.Run) diag.Diagnostics {
+	if h == nil || !h.locked {
+		return nil
+	}
+
+	client, ws := h.client, h.workspace
+	logger := h.logger.With("run_id", run.ID)
+
+	// The lock may have changed hands since we looked.
+	holder, err := readWorkspaceLockHolder(ctx, h.api, ws.ID)
+	if err != nil {
+		return diag.Errorf("Failed to retrieve the lock holder of workspace %q: %s", ws.Name, err)
+	}
+	if !h.ours(holder) {
+		logger.Info("workspace lock is no longer ours, not handing it off",
+			"lock_holder", holder.String())
+		h.locked = false
+		return nil
+	}
+
+	// Runs are queued in order, so only the runs queued before ours can
+	// start first. Those would be at the front of the queue, so we only
+	// look at the most recent page.
+	rl, err := client.Runs.List(ctx, ws.ID, tfe.RunListOptions{})
+	if err != nil {
+		return diag.Errorf("Failed to retrieve runs: %s", err)
+	}
+	for _, r := range rl.Items {
+		if r.ID == run.ID || r.Status != tfe.RunPending || !r.CreatedAt.Before(run.CreatedAt) {
+			continue
+		}
+
+		// Our run would otherwise wait behind the lock forever.
+		logger.Warn("run queued ahead of ours, discarding our run", "pending_run_id", r.ID)
+		if err := discardRun(ctx, client, run.ID); err != nil {
+			logger.Warn("error discarding our run", "error", err)
+		}
+
+		return diag.Errorf(
+			"Run %q was queued in workspace %q ahead of our run while the "+
+				"workspace was locked. Unlocking the workspace would let it "+
+				"start before our run, so our run %q was discarded. Discard "+
+				"run %q in the Terraform Cloud UI, then try again.",
+			r.ID, ws.Name, run.ID, r.ID)
+	}
+
+	logger.Info("unlocking workspace to hand the lock off to our run")
+	if _, err := client.Workspaces.Unlock(ctx, ws.ID); err != nil && err != tfe.ErrWorkspaceNotLocked {
+		return diag.Errorf("Failed to unlock workspace %q: %s", ws.Name, err)
+	}
+	h.locked = false
+	h.handedOff = true
+	h.runID = run.ID
+
+	// Wait for our run to start so that nothing else can start before it.
+	if _, diags := waitForRun(ctx, client, h.org, run, ws, true, nil, []tfe.RunStatus{
+		tfe.RunPending,
+	}); diags.HasError() {
+		return diags
+	}
+
+	if _, err := client.Workspaces.Lock(ctx, ws.ID, tfe.WorkspaceLockOptions{
+		Reason: tfe.String(h.reason),
+	}); err == nil {
+		logger.Info("locked workspace again now that our run started")
+		h.locked = true
+		return nil
+	}
+
+	// Our run holds the workspace while it is in progress, in which case
+	// we can't take the lock yet and take it back once the run is done.
+	// Anyone else holding it means the workspace was open to other runs.
+	holder, err = readWorkspaceLockHolder(ctx, h.api, ws.ID)
+	if err != nil {
+		return diag.Errorf("Failed to retrieve the lock holder of workspace %q: %s", ws.Name, err)
+	}
+	if !h.ours(holder) {
+		return diag.Errorf(
+			"Failed to lock workspace %q again after handing the lock off to "+
+				"our run %q. The workspace is locked by %s instead.",
+			ws.Name, run.ID, holder)
+	}
+
+	logger.Debug("workspace is locked by our run, locking again once it is done")
+	return nil
+}
+
+// relockAttempts is the number of times relock tries to take the lock back
+// while our run still holds it, waiting relockInterval between attempts.
+const (
+	relockAttempts = 10
+	relockInterval = 3 * time.Second
+)
+
+// relock takes the lock back if it was handed off to our run and isn't
+// ours again yet. This must be called once we're done with the workspace,
+// even if our run failed.
+func (h *lockHandoff) relock() diag.Diagnostics {
+	if h == nil || !h.handedOff || h.locked {
+		return nil
+	}
+
+	// We use a fresh context because we want to take the lock back even
+	// if the run was canceled or timed out.
+	ctx := context.Background()
+	ws := h.workspace
+	h.logger.Info("locking workspace again after our run")
+	for attempt := 1; ; attempt++ {
+		_, err := h.client.Workspaces.Lock(ctx, ws.ID, tfe.WorkspaceLockOptions{
+			Reason: tfe.String(h.reason),
+		})
+		if err == nil {
+			h.locked = true
+			return nil
+		}
+
+		holder, herr := readWorkspaceLockHolder(ctx, h.api, ws.ID)
+		if herr != nil {
+			return diag.Errorf(
+				"Failed to lock workspace %q again after our run: %s. The "+
+					"lock holder couldn't be retrieved either: %s. Lock the "+
+					"workspace again in the Terraform Cloud UI.",
+				ws.Name, err, herr)
+		}
+
+		// We already hold the lock if someone took it for us.
+		if holder != nil && holder.Type == "users" && holder.ID == h.user {
+			h.locked = true
+			return nil
+		}
+
+		// Our run may still be finishing, in which case we try again.
+		if h.ours(holder) && attempt < relockAttempts {
+			time.Sleep(relockInterval)
+			continue
+		}
+
+		return diag.Errorf(
+			"Failed to lock workspace %q again after our run: %s. The "+
+				"workspace is locked by %s, so other runs may start before "+
+				"it is locked again. Lock the workspace again in the "+
+				"Terraform Cloud UI.",
+			ws.Name, err, holder)
+	}
+}
@@ -0,0 +1,79 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestReadWorkspaceLockHolder(t *testing.T) {
+	body := ""
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v2/workspaces/ws-1" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		fmt.Fprint(w, body)
+	}))
+	defer srv.Close()
+
+	addr, err := url.Parse(srv.URL + "/api/v2/")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	api := &apiClient{address: addr, token: "secret", http: srv.Client()}
+
+	body = `{"data": {"id": "ws-1", "attributes": {"locked": false}}}`
+	holder, err := readWorkspaceLockHolder(context.Background(), api, "ws-1")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if holder != nil {
+		t.Fatalf("expected no holder, got %#v", holder)
+	}
+
+	body = `{"data": {"id": "ws-1", "attributes": {"locked": true}, ` +
+		`"relationships": {"locked-by": {"data": {"id": "run-abc", "type": "runs"}}}}}`
+	holder, err = readWorkspaceLockHolder(context.Background(), api, "ws-1")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if holder == nil || holder.Type != "runs" || holder.ID != "run-abc" {
+		t.Fatalf("bad: %#v", holder)
+	}
+	if actual := holder.String(); actual != `run "run-abc"` {
+		t.Fatalf("bad: %s", actual)
+	}
+
+	if _, err := readWorkspaceLockHolder(context.Background(), api, "ws-2"); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestLockHandoffOurs(t *testing.T) {
+	h := &lockHandoff{user: "user-me", runID: "run-ours"}
+
+	cases := []struct {
+		Holder   *workspaceLockHolder
+		Expected bool
+	}{
+		{nil, false},
+		{&workspaceLockHolder{Type: "users", ID: "user-me"}, true},
+		{&workspaceLockHolder{Type: "users", ID: "user-other"}, false},
+		{&workspaceLockHolder{Type: "teams", ID: "team-me"}, false},
+		{&workspaceLockHolder{Type: "runs", ID: "run-ours"}, true},
+		{&workspaceLockHolder{Type: "runs", ID: "run-vcs"}, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Holder.String(), func(t *testing.T) {
+			if actual := h.ours(tc.Holder); actual != tc.Expected {
+				t.Fatalf("expected %v, got %v", tc.Expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package provider
+
+import (
+	"os"
+
+	"github.com/hashicorp/go-hclog"
+)
+
+// logger is the root logger for the provider. Everything the provider logs
+// goes through this under the "multispace" subsystem. Lines are written as
+// JSON so that Terraform keeps the structured fields attached to each line.
+//
+// Subsystems of the provider should use a named sub-logger (for example
+// "multispace.run") and attach context using With. Loggers are passed down
+// call chains using hclog.WithContext and hclog.FromContext.
+var logger = hclog.New(&hclog.LoggerOptions{
+	Name:       "multispace",
+	Level:      hclog.Trace,
+	Output:     stderr{},
+	JSONFormat: true,
+})
+
+// stderr writes to the current os.Stderr. The plugin server replaces
+// os.Stderr after we're initialized so we can't capture it once.
+type stderr struct{}
+
+func (stderr) Write(p []byte) (int, error) {
+	return os.Stderr.Write(p)
+}
@@ -0,0 +1,66 @@
+package provider
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+	"time"
+)
+
+const (
+	defaultRunMessage = "terraform-provider-multispace" +
+		"{{if .Rollback}} rollback to {{.Rollback}}{{end}} on {{.Date}}" +
+		"{{if .Parent.RunID}} from {{.Parent.Workspace}} run {{.Parent.RunID}}{{end}}"
+	defaultApplyComment = "terraform-provider-multispace" +
+		"{{if .Rollback}} rollback to {{.Rollback}}{{end}} on {{.Date}}"
+)
+
+// runMessageData is the data available to the message and apply_comment
+// templates.
+type runMessageData struct {
+	Organization string
+	Workspace    string
+	Attempt      int
+	Destroy      bool
+	Date         string
+	Metadata     map[string]string
+	Parent       runSource
+
+	// Rollback is the ID of the configuration version being rolled back
+	// to. This is empty unless this is a rollback run.
+	Rollback string
+}
+
+// renderRunMessage renders a message or apply_comment template.
+func renderRunMessage(tpl string, data *runMessageData) (string, error) {
+	t, err := parseRunMessage(tpl)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+func parseRunMessage(tpl string) (*template.Template, error) {
+	return template.New("message").Option("missingkey=zero").Parse(tpl)
+}
+
+// validateRunMessage is a schema.SchemaValidateFunc that verifies a message
+// template parses.
+func validateRunMessage(v interface{}, k string) ([]string, []error) {
+	if _, err := parseRunMessage(v.(string)); err != nil {
+		return nil, []error{fmt.Errorf("%s: invalid template: %s", k, err)}
+	}
+
+	return nil, nil
+}
+
+// runMessageDate is the date format used for the Date template field.
+func runMessageDate() string {
+	return time.Now().Format("Mon Jan 2 15:04:05 MST 2006")
+}
@@ -0,0 +1,109 @@
+package provider
+
+import (
+	"testing"
+)
+
+func TestRenderRunMessage(t *testing.T) {
+	data := &runMessageData{
+		Organization: "my-org",
+		Workspace:    "network",
+		Attempt:      2,
+		Date:         "today",
+		Metadata:     map[string]string{"job": "https://ci.example.com/1"},
+	}
+
+	cases := []struct {
+		Template string
+		Expected string
+	}{
+		{
+			defaultRunMessage,
+			"terraform-provider-multispace on today",
+		},
+
+		{
+			"{{.Parent.Workspace}} {{.Parent.RunID}}",
+			" ",
+		},
+
+		{
+			"{{.Organization}}/{{.Workspace}} attempt {{.Attempt}}",
+			"my-org/network attempt 2",
+		},
+
+		{
+			"job: {{.Metadata.job}}",
+			"job: https://ci.example.com/1",
+		},
+
+		{
+			"missing: {{.Metadata.nope}}",
+			"missing: ",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Template, func(t *testing.T) {
+			actual, err := renderRunMessage(tc.Template, data)
+			if err != nil {
+				t.Fatalf("err: %s", err)
+			}
+			if actual != tc.Expected {
+				t.Fatalf("bad: %q", actual)
+			}
+		})
+	}
+}
+
+func TestValidateRunMessage(t *testing.T) {
+	if _, errs := validateRunMessage("{{.Workspace}}", "message"); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if _, errs := validateRunMessage("{{.Workspace", "message"); len(errs) == 0 {
+		t.Fatal("expected error")
+	}
+}
+
+func TestRenderRunMessage_parent(t *testing.T) {
+	data := &runMessageData{
+		Date: "today",
+		Parent: runSource{
+			RunID:        "run-abc",
+			Organization: "my-org",
+			Workspace:    "root",
+		},
+	}
+
+	actual, err := renderRunMessage(defaultRunMessage, data)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := "terraform-provider-multispace on today from root run run-abc"
+	if actual != expected {
+		t.Fatalf("bad: %q", actual)
+	}
+}
+
+func TestRenderRunMessage_rollback(t *testing.T) {
+	data := &runMessageData{
+		Date:     "today",
+		Rollback: "cv-abc",
+	}
+
+	cases := map[string]string{
+		defaultRunMessage:   "terraform-provider-multispace rollback to cv-abc on today",
+		defaultApplyComment: "terraform-provider-multispace rollback to cv-abc on today",
+	}
+
+	for tpl, expected := range cases {
+		actual, err := renderRunMessage(tpl, data)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if actual != expected {
+			t.Fatalf("bad: %q", actual)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package provider
 import (
 	"context"
 
+	"github.com/hashicorp/go-tfe"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -34,10 +35,37 @@ func New(version string) func() *schema.Provider {
 					Optional:    true,
 					Description: descriptions["ssl_skip_verify"],
 				},
+
+				"allow_destroy": {
+					Type:        schema.TypeBool,
+					Optional:    true,
+					Default:     true,
+					Description: descriptions["allow_destroy"],
+				},
+			},
+
+			DataSourcesMap: map[string]*schema.Resource{
+				"multispace_cost_estimate":    dataSourceCostEstimate(),
+				"multispace_drift":            dataSourceDrift(),
+				"multispace_org_queue":        dataSourceOrgQueue(),
+				"multispace_outputs":          dataSourceOutputs(),
+				"multispace_plan":             dataSourcePlan(),
+				"multispace_run":              dataSourceRun(),
+				"multispace_runs":             dataSourceRuns(),
+				"multispace_state_resources":  dataSourceStateResources(),
+				"multispace_workspace_graph":  dataSourceWorkspaceGraph(),
+				"multispace_workspace_status": dataSourceWorkspaceStatus(),
 			},
 
 			ResourcesMap: map[string]*schema.Resource{
-				"multispace_run": resourceRun(),
+				"multispace_approval":  resourceApproval(),
+				"multispace_cascade":   resourceCascade(),
+				"multispace_run":       resourceRun(),
+				"multispace_run_group": resourceRunGroup(),
+
+				"multispace_teardown":       resourceTeardown(),
+				"multispace_wait":           resourceWait(),
+				"multispace_workspace_lock": resourceWorkspaceLock(),
 			},
 		}
 
@@ -56,13 +84,42 @@ func configure(version string, p *schema.Provider) func(context.Context, *schema
 		token := d.Get("token").(string)
 		insecure := d.Get("ssl_skip_verify").(bool)
 
-		client, err := getClient(version, hostname, token, insecure)
+		meta, err := getMeta(version, hostname, token, insecure)
 		if err != nil {
 			return nil, diag.Errorf("%s", err.Error())
 		}
+		meta.allowDestroy = d.Get("allow_destroy").(bool)
+
+		return meta, nil
+	}
+}
+
+// providerMeta is the configured provider that is passed to every resource
+// and data source as meta.
+type providerMeta struct {
+	client *tfe.Client
+
+	// allowDestroy is false if every destroy run must be refused.
+	allowDestroy bool
 
-		return client, nil
+	// webAddress is the base address of the Terraform Enterprise web UI,
+	// such as "https://app.terraform.io".
+	webAddress string
+
+	// api makes requests to API endpoints that go-tfe doesn't support.
+	api *apiClient
+}
+
+// webURL returns the web UI URL for the given path, such as
+// "app/my-org/workspaces/foo". This uses app.terraform.io if the web
+// address isn't known.
+func (m *providerMeta) webURL(path string) string {
+	base := m.webAddress
+	if base == "" {
+		base = "https://" + defaultHostname
 	}
+
+	return base + "/" + path
 }
 
 var descriptions = map[string]string{
@@ -70,4 +127,7 @@ var descriptions = map[string]string{
 	"token": "The token used to authenticate with Terraform Enterprise. We recommend omitting\n" +
 		"the token which can be set as credentials in the CLI config file.",
 	"ssl_skip_verify": "Whether or not to skip certificate verifications.",
+	"allow_destroy": "Whether resources of this provider may queue destroy runs. " +
+		"If false, every destroy run is refused, which can be set per " +
+		"environment from a variable. Defaults to true.",
 }
@@ -0,0 +1,470 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// approvalStatus is the result of checking an approval source once.
+type approvalStatus string
+
+const (
+	approvalPending  approvalStatus = "pending"
+	approvalApproved approvalStatus = "approved"
+	approvalDenied   approvalStatus = "denied"
+)
+
+// approvalSource checks an external source for an approval.
+type approvalSource func(context.Context) (approvalStatus, error)
+
+func resourceApproval() *schema.Resource {
+	return &schema.Resource{
+		Description: "External approval gate (create)",
+
+		CreateContext: resourceApprovalCreate,
+		ReadContext:   resourceApprovalRead,
+		DeleteContext: resourceApprovalDelete,
+
+		Schema: map[string]*schema.Schema{
+			"http": {
+				Description:  approvalDescriptions["http"],
+				Type:         schema.TypeList,
+				Optional:     true,
+				ForceNew:     true,
+				MaxItems:     1,
+				ExactlyOneOf: []string{"http", "file", "run_comment"},
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"url": {
+							Description:  approvalDescriptions["http.url"],
+							Type:         schema.TypeString,
+							Required:     true,
+							ForceNew:     true,
+							ValidateFunc: validation.IsURLWithHTTPorHTTPS,
+						},
+
+						"headers": {
+							Description: approvalDescriptions["http.headers"],
+							Type:        schema.TypeMap,
+							Optional:    true,
+							ForceNew:    true,
+							Sensitive:   true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+						},
+					},
+				},
+			},
+
+			"file": {
+				Description:  approvalDescriptions["file"],
+				Type:         schema.TypeList,
+				Optional:     true,
+				ForceNew:     true,
+				MaxItems:     1,
+				ExactlyOneOf: []string{"http", "file", "run_comment"},
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"path": {
+							Description: approvalDescriptions["file.path"],
+							Type:        schema.TypeString,
+							Required:    true,
+							ForceNew:    true,
+						},
+					},
+				},
+			},
+
+			"run_comment": {
+				Description:  approvalDescriptions["run_comment"],
+				Type:         schema.TypeList,
+				Optional:     true,
+				ForceNew:     true,
+				MaxItems:     1,
+				ExactlyOneOf: []string{"http", "file", "run_comment"},
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"run_id": {
+							Description: approvalDescriptions["run_comment.run_id"],
+							Type:        schema.TypeString,
+							Required:    true,
+							ForceNew:    true,
+						},
+
+						"users": {
+							Description: approvalDescriptions["run_comment.users"],
+							Type:        schema.TypeSet,
+							Required:    true,
+							ForceNew:    true,
+							MinItems:    1,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+						},
+
+						"keyword": {
+							Description:  approvalDescriptions["run_comment.keyword"],
+							Type:         schema.TypeString,
+							Optional:     true,
+							ForceNew:     true,
+							Default:      "approved",
+							ValidateFunc: validation.StringIsNotWhiteSpace,
+						},
+
+						"deny_keyword": {
+							Description:  approvalDescriptions["run_comment.deny_keyword"],
+							Type:         schema.TypeString,
+							Optional:     true,
+							ForceNew:     true,
+							Default:      "denied",
+							ValidateFunc: validation.StringIsNotWhiteSpace,
+						},
+					},
+				},
+			},
+
+			"poll_interval": {
+				Description:  approvalDescriptions["poll_interval"],
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ForceNew:     true,
+				Default:      10,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+
+			"triggers": {
+				Description: approvalDescriptions["triggers"],
+				Type:        schema.TypeMap,
+				Optional:    true,
+				ForceNew:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+
+			"approved_at": {
+				Description: approvalDescriptions["approved_at"],
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(60 * time.Minute),
+		},
+	}
+}
+
+func resourceApprovalCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	var source approvalSource
+	var desc string
+	if v, ok := d.GetOk("http.0.url"); ok {
+		headers := map[string]string{}
+		for k, v := range d.Get("http.0.headers").(map[string]interface{}) {
+			headers[k] = v.(string)
+		}
+
+		desc = v.(string)
+		source = httpApprovalSource(v.(string), headers)
+	} else if v, ok := d.GetOk("run_comment.0.run_id"); ok {
+		api := meta.(*providerMeta).api
+		var users []string
+		for _, u := range d.Get("run_comment.0.users").(*schema.Set).List() {
+			users = append(users, u.(string))
+		}
+
+		desc = "comments on run " + v.(string)
+		source = runCommentApprovalSource(
+			api,
+			v.(string),
+			users,
+			d.Get("run_comment.0.keyword").(string),
+			d.Get("run_comment.0.deny_keyword").(string),
+		)
+	} else {
+		desc = d.Get("file.0.path").(string)
+		source = fileApprovalSource(desc)
+	}
+
+	logger := logger.Named("approval").With("source", desc)
+	interval := time.Duration(d.Get("poll_interval").(int)) * time.Second
+	logger.Info("waiting for approval")
+	for {
+		status, err := source(ctx)
+		if err != nil {
+			// Errors reaching the source are logged and retried since the
+			// source may simply not be up yet.
+			logger.Warn("error checking approval", "error", err)
+		}
+
+		switch status {
+		case approvalApproved:
+			logger.Info("approved")
+			d.SetId(resource.UniqueId())
+			if err := d.Set("approved_at", time.Now().UTC().Format(time.RFC3339)); err != nil {
+				return diag.FromErr(err)
+			}
+
+			return nil
+
+		case approvalDenied:
+			return diag.Errorf("Approval was denied by %s", desc)
+		}
+
+		select {
+		case <-ctx.Done():
+			return diag.Errorf("Timed out waiting for approval from %s: %s", desc, ctx.Err())
+		case <-time.After(interval):
+		}
+	}
+}
+
+func resourceApprovalRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// An approval is historical so there is nothing to refresh.
+	return nil
+}
+
+func resourceApprovalDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// Destroying an approval doesn't require another approval.
+	return nil
+}
+
+// approvalHTTPTimeout is how long a single check of an HTTP approval
+// source may take, so an endpoint that never responds doesn't stall the
+// polling until the resource times out.
+const approvalHTTPTimeout = 30 * time.Second
+
+// approvalHTTPClient is the client used to check HTTP approval sources.
+var approvalHTTPClient = &http.Client{Timeout: approvalHTTPTimeout}
+
+// httpApprovalSource checks an HTTP endpoint for an approval. The endpoint
+// must respond with a 200 status and a body of "approved" or "denied".
+// Anything else is treated as still pending.
+func httpApprovalSource(url string, headers map[string]string) approvalSource {
+	return func(ctx context.Context) (approvalStatus, error) {
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+		if err != nil {
+			return approvalPending, err
+		}
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+
+		resp, err := approvalHTTPClient.Do(req)
+		if err != nil {
+			return approvalPending, err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return approvalPending, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+		}
+
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return approvalPending, err
+		}
+
+		return parseApprovalStatus(string(body), approvalPending), nil
+	}
+}
+
+// fileApprovalSource checks for a file at path. The file existing is an
+// approval unless its contents are "denied".
+func fileApprovalSource(path string) approvalSource {
+	return func(ctx context.Context) (approvalStatus, error) {
+		body, err := ioutil.ReadFile(path)
+		if os.IsNotExist(err) {
+			return approvalPending, nil
+		}
+		if err != nil {
+			return approvalPending, err
+		}
+
+		return parseApprovalStatus(string(body), approvalApproved), nil
+	}
+}
+
+// runCommentApprovalSource checks the comments on a run for an approval
+// from one of the given users. A comment whose first line is the keyword,
+// compared case-insensitively ignoring surrounding whitespace, approves.
+// A comment whose first line is denyKeyword denies, and a denial wins over
+// an approval. Comments from other users are ignored.
+//
+// go-tfe doesn't support comments, so the API is requested directly.
+// Comments don't include their author, which is the actor of the run
+// event that each comment belongs to.
+func runCommentApprovalSource(api *apiClient, runID string, users []string, keyword, denyKeyword string) approvalSource {
+	allowed := map[string]struct{}{}
+	for _, u := range users {
+		allowed[u] = struct{}{}
+	}
+
+	// Run events and their actors never change, so we remember the author
+	// of each comment we've already seen.
+	authors := map[string]string{}
+
+	return func(ctx context.Context) (approvalStatus, error) {
+		path := "runs/" + url.PathEscape(runID) + "/comments"
+		query := url.Values{}
+		result := approvalPending
+		for {
+			var comments struct {
+				Data []struct {
+					ID         string `json:"id"`
+					Attributes struct {
+						Body string `json:"body"`
+					} `json:"attributes"`
+					Relationships struct {
+						RunEvent struct {
+							Data struct {
+								ID string `json:"id"`
+							} `json:"data"`
+						} `json:"run-event"`
+					} `json:"relationships"`
+				} `json:"data"`
+				Meta struct {
+					Pagination *apiPagination `json:"pagination"`
+				} `json:"meta"`
+			}
+			if err := api.get(ctx, path, query, &comments); err != nil {
+				return approvalPending, err
+			}
+
+			for _, c := range comments.Data {
+				status := commentApprovalStatus(c.Attributes.Body, keyword, denyKeyword)
+				if status == approvalPending {
+					continue
+				}
+
+				author, ok := authors[c.ID]
+				if !ok {
+					var err error
+					author, err = runEventActor(ctx, api, c.Relationships.RunEvent.Data.ID)
+					if err != nil {
+						return approvalPending, err
+					}
+
+					authors[c.ID] = author
+				}
+				if _, ok := allowed[author]; !ok {
+					continue
+				}
+
+				// A denial on any page wins so we can stop looking.
+				if status == approvalDenied {
+					return approvalDenied, nil
+				}
+				result = approvalApproved
+			}
+
+			// Exit the loop when we've seen all pages.
+			p := comments.Meta.Pagination
+			if p == nil || p.CurrentPage >= p.TotalPages {
+				break
+			}
+
+			// Update the page number to get the next page.
+			query.Set("page[number]", strconv.Itoa(p.NextPage))
+		}
+
+		return result, nil
+	}
+}
+
+// runEventActor returns the username of the user that caused a run event.
+func runEventActor(ctx context.Context, api *apiClient, id string) (string, error) {
+	if id == "" {
+		return "", nil
+	}
+
+	var event struct {
+		Data struct {
+			Relationships struct {
+				Actor struct {
+					Data struct {
+						ID string `json:"id"`
+					} `json:"data"`
+				} `json:"actor"`
+			} `json:"relationships"`
+		} `json:"data"`
+		Included []struct {
+			ID         string `json:"id"`
+			Type       string `json:"type"`
+			Attributes struct {
+				Username string `json:"username"`
+			} `json:"attributes"`
+		} `json:"included"`
+	}
+	path := "run-events/" + url.PathEscape(id)
+	if err := api.get(ctx, path, url.Values{"include": {"actor"}}, &event); err != nil {
+		return "", err
+	}
+
+	for _, inc := range event.Included {
+		if inc.Type == "users" && inc.ID == event.Data.Relationships.Actor.Data.ID {
+			return inc.Attributes.Username, nil
+		}
+	}
+
+	return "", nil
+}
+
+// commentApprovalStatus returns whether the first line of a comment body
+// is the keyword or denyKeyword.
+func commentApprovalStatus(body, keyword, denyKeyword string) approvalStatus {
+	line := strings.TrimSpace(strings.SplitN(strings.TrimSpace(body), "\n", 2)[0])
+	switch {
+	case strings.EqualFold(line, strings.TrimSpace(denyKeyword)):
+		return approvalDenied
+	case strings.EqualFold(line, strings.TrimSpace(keyword)):
+		return approvalApproved
+	default:
+		return approvalPending
+	}
+}
+
+// parseApprovalStatus parses the body of an approval response. The body
+// is compared case-insensitively ignoring surrounding whitespace. If it
+// is neither "approved" nor "denied", def is returned.
+func parseApprovalStatus(body string, def approvalStatus) approvalStatus {
+	switch approvalStatus(strings.ToLower(strings.TrimSpace(body))) {
+	case approvalApproved:
+		return approvalApproved
+	case approvalDenied:
+		return approvalDenied
+	default:
+		return def
+	}
+}
+
+var approvalDescriptions = map[string]string{
+	"http": "Wait for an HTTP endpoint to approve. The endpoint is requested " +
+		"with `GET` and must respond with status 200 and a body of `approved` " +
+		"or `denied`. Any other response is treated as pending.",
+	"http.url":     "The URL to request.",
+	"http.headers": "Headers to send with each request, such as an authorization header.",
+	"file": "Wait for a file to appear. The file appearing is an approval " +
+		"unless its contents are `denied`.",
+	"file.path": "The path of the file to wait for.",
+	"run_comment": "Wait for a comment on a Terraform Cloud run from one of " +
+		"the allowed users. A comment whose first line is the keyword approves " +
+		"and a comment whose first line is the deny keyword denies. Both are " +
+		"compared case-insensitively, and a denial wins over an approval.",
+	"run_comment.run_id": "The ID of the run to watch for comments, such as the " +
+		"`id` of a `multispace_run`.",
+	"run_comment.users":        "The usernames of the users allowed to approve or deny.",
+	"run_comment.keyword":      "The first line of a comment that approves.",
+	"run_comment.deny_keyword": "The first line of a comment that denies.",
+	"poll_interval":            "The number of seconds to wait between checks for an approval.",
+	"triggers": "Arbitrary values that require a new approval when they change, " +
+		"such as the ID of the run being approved.",
+	"approved_at": "The time the approval was seen, in RFC 3339 format.",
+}
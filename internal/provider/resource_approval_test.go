@@ -0,0 +1,274 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestParseApprovalStatus(t *testing.T) {
+	cases := []struct {
+		Body     string
+		Default  approvalStatus
+		Expected approvalStatus
+	}{
+		{"approved", approvalPending, approvalApproved},
+		{" Approved\n", approvalPending, approvalApproved},
+		{"DENIED", approvalPending, approvalDenied},
+		{"", approvalPending, approvalPending},
+		{"", approvalApproved, approvalApproved},
+		{"maybe", approvalPending, approvalPending},
+	}
+
+	for _, tc := range cases {
+		t.Run(fmt.Sprintf("%q", tc.Body), func(t *testing.T) {
+			actual := parseApprovalStatus(tc.Body, tc.Default)
+			if actual != tc.Expected {
+				t.Fatalf("expected %q, got %q", tc.Expected, actual)
+			}
+		})
+	}
+}
+
+func TestHTTPApprovalSource(t *testing.T) {
+	body := "pending"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		fmt.Fprint(w, body)
+	}))
+	defer srv.Close()
+
+	source := httpApprovalSource(srv.URL, map[string]string{
+		"Authorization": "Bearer secret",
+	})
+
+	status, err := source(context.Background())
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if status != approvalPending {
+		t.Fatalf("expected pending, got %q", status)
+	}
+
+	body = "approved"
+	status, err = source(context.Background())
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if status != approvalApproved {
+		t.Fatalf("expected approved, got %q", status)
+	}
+
+	// Without the header we get a non-200 status which is an error.
+	status, err = httpApprovalSource(srv.URL, nil)(context.Background())
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if status != approvalPending {
+		t.Fatalf("expected pending, got %q", status)
+	}
+}
+
+func TestFileApprovalSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "approval")
+	source := fileApprovalSource(path)
+
+	status, err := source(context.Background())
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if status != approvalPending {
+		t.Fatalf("expected pending, got %q", status)
+	}
+
+	if err := ioutil.WriteFile(path, nil, 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	status, err = source(context.Background())
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if status != approvalApproved {
+		t.Fatalf("expected approved, got %q", status)
+	}
+
+	if err := ioutil.WriteFile(path, []byte("denied\n"), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	status, err = source(context.Background())
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if status != approvalDenied {
+		t.Fatalf("expected denied, got %q", status)
+	}
+}
+
+func TestCommentApprovalStatus(t *testing.T) {
+	cases := []struct {
+		Body     string
+		Expected approvalStatus
+	}{
+		{"approved", approvalApproved},
+		{" Approved \nLooks good to me.", approvalApproved},
+		{"DENIED", approvalDenied},
+		{"not approved", approvalPending},
+		{"Looks good.\napproved", approvalPending},
+		{"", approvalPending},
+	}
+
+	for _, tc := range cases {
+		t.Run(fmt.Sprintf("%q", tc.Body), func(t *testing.T) {
+			actual := commentApprovalStatus(tc.Body, "approved", "denied")
+			if actual != tc.Expected {
+				t.Fatalf("expected %q, got %q", tc.Expected, actual)
+			}
+		})
+	}
+}
+
+func TestRunCommentApprovalSource(t *testing.T) {
+	comments := []string{}
+	actors := map[string]string{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch {
+		case r.URL.Path == "/api/v2/runs/run-1/comments":
+			// Serve two comments per page so later comments are only
+			// seen by paging.
+			page := 1
+			if v := r.URL.Query().Get("page[number]"); v != "" {
+				page, _ = strconv.Atoi(v)
+			}
+			total := (len(comments) + 1) / 2
+			if total == 0 {
+				total = 1
+			}
+			start := (page - 1) * 2
+			if start > len(comments) {
+				start = len(comments)
+			}
+			end := start + 2
+			if end > len(comments) {
+				end = len(comments)
+			}
+			fmt.Fprintf(w,
+				`{"data":[%s],"meta":{"pagination":{"current-page":%d,"next-page":%d,"total-pages":%d}}}`,
+				strings.Join(comments[start:end], ","), page, page+1, total)
+
+		case strings.HasPrefix(r.URL.Path, "/api/v2/run-events/"):
+			if r.URL.Query().Get("include") != "actor" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			id := strings.TrimPrefix(r.URL.Path, "/api/v2/run-events/")
+			fmt.Fprintf(w, `{
+  "data": {"id": %q, "type": "run-events", "relationships": {"actor": {"data": {"id": "user-%[2]s", "type": "users"}}}},
+  "included": [{"id": "user-%[2]s", "type": "users", "attributes": {"username": %[2]q}}]
+}`, id, actors[id])
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	addr, err := url.Parse(srv.URL + "/api/v2/")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	api := &apiClient{address: addr, token: "secret", http: srv.Client()}
+
+	comment := func(id, body, actor string) {
+		actors["re-"+id] = actor
+		comments = append(comments, fmt.Sprintf(
+			`{"id": %q, "type": "comments", "attributes": {"body": %q}, `+
+				`"relationships": {"run-event": {"data": {"id": %q, "type": "run-events"}}}}`,
+			"wsc-"+id, body, "re-"+id))
+	}
+
+	check := func(expected approvalStatus) {
+		t.Helper()
+
+		source := runCommentApprovalSource(api, "run-1", []string{"alice"}, "approved", "denied")
+		status, err := source(context.Background())
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if status != expected {
+			t.Fatalf("expected %q, got %q", expected, status)
+		}
+	}
+
+	check(approvalPending)
+
+	// Comments from users that aren't allowed are ignored.
+	comment("1", "approved", "mallory")
+	comment("2", "Can someone approve this?", "alice")
+	check(approvalPending)
+
+	comment("3", "Approved\nPlan looks good.", "alice")
+	check(approvalApproved)
+
+	// A denial wins over an approval.
+	comment("4", "Looks fine to me", "bob")
+	comment("5", "denied", "alice")
+	check(approvalDenied)
+
+	// Errors reaching the API are returned as pending.
+	status, err := runCommentApprovalSource(api, "run-2", []string{"alice"}, "approved", "denied")(context.Background())
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if status != approvalPending {
+		t.Fatalf("expected pending, got %q", status)
+	}
+}
+
+func TestAccResourceApproval(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "approval")
+	if err := ioutil.WriteFile(path, []byte("approved"), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testAccResourceApproval, path),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet("multispace_approval.gate", "approved_at"),
+				),
+			},
+		},
+	})
+}
+
+const testAccResourceApproval = `
+resource "multispace_approval" "gate" {
+  poll_interval = 1
+
+  file {
+    path = %q
+  }
+}
+`
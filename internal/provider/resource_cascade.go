@@ -0,0 +1,268 @@
+package provider
+
+import (
+	"context"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	tfe "github.com/hashicorp/go-tfe"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func resourceCascade() *schema.Resource {
+	s := map[string]*schema.Schema{
+		"organization": {
+			Description: cascadeDescriptions["organization"],
+			Type:        schema.TypeString,
+			Required:    true,
+			ForceNew:    true,
+		},
+
+		"root_workspace": {
+			Description: cascadeDescriptions["root_workspace"],
+			Type:        schema.TypeString,
+			Required:    true,
+			ForceNew:    true,
+		},
+
+		"max_parallelism": {
+			Description:  cascadeDescriptions["max_parallelism"],
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Default:      10,
+			ValidateFunc: validation.IntAtLeast(1),
+		},
+
+		"discard_triggered_runs": {
+			Description: cascadeDescriptions["discard_triggered_runs"],
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     true,
+		},
+
+		"workspaces": {
+			Description: cascadeDescriptions["workspaces"],
+			Type:        schema.TypeList,
+			Computed:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+		},
+
+		"runs": {
+			Description: cascadeDescriptions["runs"],
+			Type:        schema.TypeMap,
+			Computed:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+		},
+	}
+
+	// Share the run settings with multispace_run.
+	runSchema := resourceRun().Schema
+	for _, k := range runGroupSharedKeys {
+		s[k] = runSchema[k]
+	}
+
+	return &schema.Resource{
+		Description: "Cascading workspace runs following run triggers (create/destroy)",
+
+		CreateContext: resourceCascadeCreate,
+		ReadContext:   resourceCascadeRead,
+		UpdateContext: resourceCascadeUpdate,
+		DeleteContext: resourceCascadeDelete,
+
+		Schema: s,
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(60 * time.Minute),
+			Delete: schema.DefaultTimeout(60 * time.Minute),
+		},
+	}
+}
+
+func resourceCascadeCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	order, runs, diags := doCascade(ctx, d, meta, false)
+	if diags.HasError() {
+		// Like multispace_run, a failed create leaves nothing created so
+		// the next apply will run the cascade again.
+		return diags
+	}
+
+	d.SetId(resource.UniqueId())
+	if err := d.Set("workspaces", order); err != nil {
+		return append(diags, diag.FromErr(err)...)
+	}
+	if err := d.Set("runs", runs); err != nil {
+		return append(diags, diag.FromErr(err)...)
+	}
+
+	return diags
+}
+
+func resourceCascadeRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// The runs we queued are historical so there is nothing to refresh.
+	return nil
+}
+
+func resourceCascadeUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// Update we do nothing since we should have created during apply.
+	return nil
+}
+
+func resourceCascadeDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	_, _, diags := doCascade(ctx, d, meta, true)
+	return diags
+}
+
+// doCascade discovers the workspaces reachable from the root workspace
+// through run triggers and runs them in topological order, or reverse
+// topological order for destroy. Workspaces that don't depend on each
+// other are run concurrently. The graph is discovered each time so that
+// a destroy covers the graph as it exists at that time.
+func doCascade(
+	ctx context.Context,
+	d *schema.ResourceData,
+	meta interface{},
+	destroy bool,
+) ([]string, map[string]interface{}, diag.Diagnostics) {
+	client := meta.(*providerMeta).client
+	org := d.Get("organization").(string)
+	root := d.Get("root_workspace").(string)
+
+	graph, levels, order, err := discoverWorkspaceLevels(ctx, client, org, root, destroy)
+	if err != nil {
+		return nil, nil, diag.FromErr(err)
+	}
+
+	logger.Named("cascade").Info("discovered workspace graph",
+		"organization", org,
+		"root_workspace", root,
+		"destroy", destroy,
+		"workspaces", order,
+	)
+
+	base := runConfigFromResourceData(d, meta.(*providerMeta), destroy)
+	parallelism := d.Get("max_parallelism").(int)
+	discard := d.Get("discard_triggered_runs").(bool)
+
+	// Check every workspace before destroying any of them.
+	if destroy {
+		if diags := checkDestroyGuards(ctx, client, base.AllowDestroy, base.DestroyGuard, org, order); diags.HasError() {
+			return order, nil, diags
+		}
+	}
+
+	runs := map[string]interface{}{}
+	for _, level := range levels {
+		levelRuns, diags := doRunConcurrent(ctx, client, base, level, parallelism, true)
+		for k, v := range levelRuns {
+			runs[k] = v
+		}
+		if diags.HasError() {
+			return order, runs, diags
+		}
+
+		// Our applies queue runs in the workspaces they trigger, which are
+		// run next. On destroy, they were already destroyed and the runs
+		// would plan to recreate everything, so they're always discarded.
+		if discard || destroy {
+			if err := discardRunsTriggeredBy(ctx, client, org, graph, levelRuns); err != nil {
+				return order, runs, diag.Errorf("Failed to discard triggered runs: %s", err)
+			}
+		}
+	}
+
+	return order, runs, nil
+}
+
+// discardRunsTriggeredBy discards the runs that the applied runs, keyed by
+// workspace name, queued through run triggers in the workspaces they
+// trigger. This waits for each triggered run to be queued.
+func discardRunsTriggeredBy(
+	ctx context.Context,
+	client *tfe.Client,
+	org string,
+	graph *workspaceGraph,
+	runs map[string]interface{},
+) error {
+	for workspace, id := range runs {
+		r, err := client.Runs.Read(ctx, id.(string))
+		if err != nil {
+			return err
+		}
+
+		// Only an apply queues runs through run triggers.
+		if r.Status != tfe.RunApplied {
+			continue
+		}
+
+		for _, target := range graph.Targets(workspace) {
+			ws, err := client.Workspaces.Read(ctx, org, target)
+			if err != nil {
+				return err
+			}
+
+			logger := hclog.FromContext(ctx).With(
+				"workspace", target,
+				"source_workspace", workspace,
+				"source_run_id", r.ID,
+			)
+			ctx := hclog.WithContext(ctx, logger)
+			if err := discardTriggeredRuns(ctx, client, ws.ID, r.CreatedAt); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// discoverWorkspaceLevels discovers the workspace graph from the root and
+// returns it with its levels and every workspace in topological order. For
+// destroy, the levels are reversed so they go from the leaves back to the
+// root, but the order is not.
+func discoverWorkspaceLevels(
+	ctx context.Context,
+	client *tfe.Client,
+	org string,
+	root string,
+	destroy bool,
+) (*workspaceGraph, [][]string, []string, error) {
+	graph, err := discoverWorkspaceGraph(ctx, client, org, root)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	levels, err := graph.Levels()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var order []string
+	for _, l := range levels {
+		order = append(order, l...)
+	}
+
+	if destroy {
+		for i, j := 0, len(levels)-1; i < j; i, j = i+1, j-1 {
+			levels[i], levels[j] = levels[j], levels[i]
+		}
+	}
+
+	return graph, levels, order, nil
+}
+
+var cascadeDescriptions = map[string]string{
+	"organization": "The name of the Terraform Cloud organization that owns the workspaces.",
+	"root_workspace": "The name of the workspace to start from. Every workspace " +
+		"reachable from this workspace through run triggers is part of the cascade.",
+	"max_parallelism": "The maximum number of workspaces to run at the same time. " +
+		"Only workspaces that don't depend on each other are run at the same time.",
+	"discard_triggered_runs": "Whether to discard the runs that our applies " +
+		"queue through run triggers in downstream workspaces before queueing our " +
+		"own run. Runs queued by run triggers that wait for confirmation would " +
+		"otherwise block the cascade. On destroy, these runs are always discarded.",
+	"workspaces": "The workspaces in the cascade in the order they were applied.",
+	"runs":       "The ID of the run queued for each workspace, keyed by workspace name.",
+}
@@ -0,0 +1,29 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccResourceCascade(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceCascade,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("multispace_cascade.root", "workspaces.0", "root"),
+				),
+			},
+		},
+	})
+}
+
+const testAccResourceCascade = `
+resource "multispace_cascade" "root" {
+  organization   = "multispace-test"
+  root_workspace = "root"
+}
+`
@@ -2,8 +2,6 @@ package provider
 
 import (
 	"context"
-	"fmt"
-	"log"
 	"strings"
 	"time"
 
@@ -43,6 +41,93 @@ func resourceRun() *schema.Resource {
 				Default:     false,
 			},
 
+			"message": {
+				Description:  runDescriptions["message"],
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      defaultRunMessage,
+				ValidateFunc: validateRunMessage,
+			},
+
+			"apply_comment": {
+				Description:  runDescriptions["apply_comment"],
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      defaultApplyComment,
+				ValidateFunc: validateRunMessage,
+			},
+
+			"metadata": {
+				Description: runDescriptions["metadata"],
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+
+			"source": {
+				Description: runDescriptions["source"],
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"run_id": {
+							Description: runDescriptions["source_run_id"],
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+
+						"organization": {
+							Description: runDescriptions["source_organization"],
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+
+						"workspace": {
+							Description: runDescriptions["source_workspace"],
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+					},
+				},
+			},
+
+			"state_version_id_before": {
+				Description: runDescriptions["state_version_id_before"],
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+
+			"state_serial_before": {
+				Description: runDescriptions["state_serial_before"],
+				Type:        schema.TypeInt,
+				Computed:    true,
+			},
+
+			"state_version_id_after": {
+				Description: runDescriptions["state_version_id_after"],
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+
+			"state_serial_after": {
+				Description: runDescriptions["state_serial_after"],
+				Type:        schema.TypeInt,
+				Computed:    true,
+			},
+
+			"rollback_on_failure": {
+				Description: runDescriptions["rollback_on_failure"],
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
+
+			"prevent_destroy_unless": destroyGuardSchema(),
+
+			"temporary_variable": temporaryVariableSchema(),
+
+			"workspace_settings": workspaceSettingsSchema(),
+
 			"retry": {
 				Description: runDescriptions["retry"],
 				Type:        schema.TypeBool,
@@ -54,22 +139,26 @@ func resourceRun() *schema.Resource {
 				Description: runDescriptions["retry_attempts"],
 				Type:        schema.TypeInt,
 				Optional:    true,
-				Default:     3,
+				Default:     defaultRetryAttempts,
 			},
 
 			"retry_backoff_min": {
 				Description: runDescriptions["retry_backoff_min"],
 				Type:        schema.TypeInt,
 				Optional:    true,
-				Default:     1,
+				Default:     defaultRetryBackoffMin,
 			},
 
 			"retry_backoff_max": {
 				Description: runDescriptions["retry_backoff_max"],
 				Type:        schema.TypeInt,
 				Optional:    true,
-				Default:     30,
+				Default:     defaultRetryBackoffMax,
 			},
+
+			"retry_plan":    retryPolicySchema(runDescriptions["retry_plan"]),
+			"retry_apply":   retryPolicySchema(runDescriptions["retry_apply"]),
+			"retry_destroy": retryPolicySchema(runDescriptions["retry_destroy"]),
 		},
 
 		Timeouts: &schema.ResourceTimeout{
@@ -80,11 +169,11 @@ func resourceRun() *schema.Resource {
 }
 
 func resourceRunCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	return doRun(ctx, d, meta, false)
+	return resourceRunDo(ctx, d, meta, false)
 }
 
 func resourceRunRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	client := meta.(*tfe.Client)
+	client := meta.(*providerMeta).client
 	id := d.Id()
 
 	// Get our run. If it doesn't exist, then we assume that we were never
@@ -109,213 +198,88 @@ func resourceRunUpdate(ctx context.Context, d *schema.ResourceData, meta interfa
 }
 
 func resourceRunDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	return doRun(ctx, d, meta, true)
+	return resourceRunDo(ctx, d, meta, true)
 }
 
-func doRun(
+// resourceRunDo runs the run lifecycle for the multispace_run resource
+// and stores the result in the resource data.
+func resourceRunDo(
 	ctx context.Context,
 	d *schema.ResourceData,
 	meta interface{},
 	destroy bool,
 ) diag.Diagnostics {
-	// We only set the ID on create
-	setId := func(v string) {
-		if !destroy {
-			d.SetId(v)
-		}
-	}
+	client := meta.(*providerMeta).client
 
-	// Get our retry information
-	retry := d.Get("retry").(bool)
-	retryMaxAttempts := d.Get("retry_attempts").(int)
-	retryBOMin := d.Get("retry_backoff_min").(int)
-	retryBOMax := d.Get("retry_backoff_max").(int)
-	retryCurAttempts := 0
-
-RETRY:
-	retryCurAttempts++
-	if retryCurAttempts > 1 {
-		// If we're retrying, then perform the backoff.
-		select {
-		case <-ctx.Done():
-			return diag.FromErr(ctx.Err())
-		case <-time.After(backoff(
-			float64(retryBOMin), float64(retryBOMax), retryCurAttempts)):
-		}
-	}
-	if retryCurAttempts > retryMaxAttempts {
-		return diag.Errorf(
-			"Maximum retry attempts %d reached. Please see the web UI "+
-				"to see any errors during plan or apply.",
-			retryMaxAttempts,
-		)
-	}
-
-	client := meta.(*tfe.Client)
-	org := d.Get("organization").(string)
-	workspace := d.Get("workspace").(string)
-
-	// Get our workspace because we need it to queue a plan
-	ws, err := client.Workspaces.Read(ctx, org, workspace)
-	if err != nil {
-		return diag.FromErr(err)
-	}
-
-	// Create a run
-	run, err := client.Runs.Create(ctx, tfe.RunCreateOptions{
-		Message: tfe.String(fmt.Sprintf(
-			"terraform-provider-multispace on %s",
-			time.Now().Format("Mon Jan 2 15:04:05 MST 2006"),
-		)),
-		Workspace: ws,
-		IsDestroy: tfe.Bool(destroy),
-
-		// Never auto-apply because we handle all that.
-		AutoApply: tfe.Bool(false),
-	})
-
-	// I THINK if err != nil implies run == nil but we can never be too sure.
-	// If we have a non-nil run, we want to save the ID so we don't dangle
-	// resources.
-	if run != nil {
-		// The ID we use is the run we queue. We can use this to look this
-		// run up again in the case of a partial failure.
-		setId(run.ID)
-		log.Printf("[INFO] run created: %s", run.ID)
-	}
-
-	if err != nil {
-		return diag.FromErr(err)
-	}
-
-	// If we reach this point and run is nil, there is a bug in the TFE
-	// API client. But its non-sensical and we can't continue so we must exit.
-	if run == nil {
-		log.Printf("[ERROR] TFE client returned err nil but also run nil")
-		return diag.Errorf(
-			"INTERNAL ERROR: run create API did not error, but also did not " +
-				"return a run ID.")
-	}
+	cfg := runConfigFromResourceData(d, meta.(*providerMeta), destroy)
+	cfg.Workspace = d.Get("workspace").(string)
 
-	// Wait for the plan to complete.
-	run, diags := waitForRun(ctx, client, org, run, ws, true, []tfe.RunStatus{
-		tfe.RunPlanned,
-		tfe.RunPlannedAndFinished,
-		tfe.RunErrored,
-		tfe.RunCostEstimated,
-		tfe.RunPolicyChecked,
-		tfe.RunPolicySoftFailed,
-		tfe.RunPolicyOverride,
-	}, []tfe.RunStatus{
-		tfe.RunPending,
-		tfe.RunPlanQueued,
-		tfe.RunPlanning,
-		tfe.RunCostEstimating,
-		tfe.RunPolicyChecking,
-	})
-	if diags != nil {
-		return diags
+	// We only set the ID on create. The ID we use is the run we queue.
+	// We can use this to look this run up again in the case of a
+	// partial failure.
+	if !destroy {
+		cfg.SetID = d.SetId
 	}
 
-	// If the run errored, we should have exited already but lets just exit now.
-	if run.Status == tfe.RunErrored || run.Status == tfe.RunPolicySoftFailed {
-		// Clear the ID, we didn't create anything.
-		setId("")
+	result, diags := doRun(ctx, client, &cfg)
 
-		if retry {
-			// Retry
-			goto RETRY
+	// We only record our results on create since the resource is gone
+	// after a destroy.
+	if !destroy && result != nil {
+		if err := d.Set("source", result.Source.flatten()); err != nil {
+			diags = append(diags, diag.FromErr(err)...)
 		}
 
-		return diag.Errorf(
-			"Run %q errored during plan. Please open the web UI to view the error",
-			run.ID,
-		)
-	}
-
-	// If the plan has no changes, then we're done.
-	if !run.HasChanges || run.Status == tfe.RunPlannedAndFinished {
-		log.Printf("[INFO] plan finished, no changes")
-		return nil
-	}
-
-	// If a policy soft-fails, we need human approval before we continue
-	if run.Status == tfe.RunPolicyOverride {
-		log.Printf("[INFO] policy check soft-failed, waiting for manual override. %q", run.ID)
-		run, diags = waitForRun(ctx, client, org, run, ws, true, []tfe.RunStatus{
-			tfe.RunConfirmed,
-			tfe.RunApplyQueued,
-			tfe.RunApplying,
-		}, []tfe.RunStatus{run.Status})
-		if diags != nil {
-			return diags
-		}
-	}
-
-	// If we're doing a manual confirmation, then we wait for the human to confirm.
-	if !destroy && d.Get("manual_confirm").(bool) {
-		log.Printf("[INFO] plan complete, waiting for manual confirm. %q", run.ID)
-		run, diags = waitForRun(ctx, client, org, run, ws, true, []tfe.RunStatus{
-			tfe.RunConfirmed,
-			tfe.RunApplyQueued,
-			tfe.RunApplying,
-		}, []tfe.RunStatus{run.Status})
-		if diags != nil {
-			return diags
-		}
-	} else {
-		// Apply the plan.
-		log.Printf("[INFO] plan complete, confirming apply. %q", run.ID)
-		if err := client.Runs.Apply(ctx, run.ID, tfe.RunApplyOptions{
-			Comment: tfe.String(fmt.Sprintf(
-				"terraform-provider-multispace on %s",
-				time.Now().Format("Mon Jan 2 15:04:05 MST 2006"),
-			)),
-		}); err != nil {
-			return diag.FromErr(err)
+		for suffix, sv := range map[string]*tfe.StateVersion{
+			"before": result.StateBefore,
+			"after":  result.StateAfter,
+		} {
+			id, serial := "", int64(0)
+			if sv != nil {
+				id, serial = sv.ID, sv.Serial
+			}
+			if err := d.Set("state_version_id_"+suffix, id); err != nil {
+				diags = append(diags, diag.FromErr(err)...)
+			}
+			if err := d.Set("state_serial_"+suffix, int(serial)); err != nil {
+				diags = append(diags, diag.FromErr(err)...)
+			}
 		}
 	}
 
-	// Wait now for the apply to complete
-	run, diags = waitForRun(ctx, client, org, run, ws, false, []tfe.RunStatus{
-		tfe.RunApplied,
-		tfe.RunErrored,
-	}, []tfe.RunStatus{
-		tfe.RunConfirmed,
-		tfe.RunApplyQueued,
-		tfe.RunApplying,
-	})
-	if diags != nil {
-		return diags
-	}
-
-	// If the run errored, we should have exited already but lets just exit now.
-	if run.Status == tfe.RunErrored {
-		// Clear the ID, we didn't create anything.
-		setId("")
-
-		if retry {
-			// Retry
-			goto RETRY
-		}
+	return diags
+}
 
-		return diag.Errorf(
-			"Run %q errored during apply. Please open the web UI to view the error",
-			run.ID,
-		)
+// runConfigFromResourceData reads the run settings that are shared by
+// every resource that runs workspaces, along with the provider settings
+// that apply to runs. The workspace is not set.
+func runConfigFromResourceData(d *schema.ResourceData, meta *providerMeta, destroy bool) runConfig {
+	metadata := map[string]string{}
+	for k, v := range d.Get("metadata").(map[string]interface{}) {
+		metadata[k] = v.(string)
 	}
 
-	// If this is not applied, we're in some unexpected state.
-	if run.Status != tfe.RunApplied {
-		setId("")
-
-		return diag.Errorf(
-			"Run %q entered unexpected state %q, expected applied",
-			run.ID, run.Status,
-		)
+	// Not every resource that runs workspaces has these settings, so we
+	// use GetOk, which is only ok for a bool that is true.
+	_, manualConfirm := d.GetOk("manual_confirm")
+	_, rollbackOnFailure := d.GetOk("rollback_on_failure")
+
+	return runConfig{
+		Organization:  d.Get("organization").(string),
+		Destroy:       destroy,
+		ManualConfirm: manualConfirm,
+		Message:       d.Get("message").(string),
+		ApplyComment:  d.Get("apply_comment").(string),
+		Metadata:      metadata,
+		Retry:         retryPolicies(d, destroy),
+
+		RollbackOnFailure: rollbackOnFailure,
+		AllowDestroy:      meta.allowDestroy,
+		API:               meta.api,
+		DestroyGuard:      readDestroyGuard(d),
+		Variables:         readTemporaryVariables(d),
+		Settings:          readWorkspaceSettings(d),
 	}
-
-	return nil
 }
 
 var runDescriptions = map[string]string{
@@ -326,11 +290,45 @@ var runDescriptions = map[string]string{
 		"requires manual confirmation. This requires a human to carefully watch the execution " +
 		"of this Terraform run and hit the 'confirm' button. Be aware of resource " +
 		"timeouts during the Terraform run.",
+	"message": "The message for the queued run. This is a Go template with " +
+		"the fields `.Organization`, `.Workspace`, `.Attempt`, `.Destroy`, " +
+		"`.Date`, `.Metadata`, `.Parent`, and `.Rollback` available.",
+	"apply_comment": "The comment used when confirming the apply. This is a " +
+		"template with the same fields as `message`.",
+	"metadata": "Arbitrary key/value metadata available to the `message` and " +
+		"`apply_comment` templates as `.Metadata`, such as a CI job URL.",
+	"source": "The Terraform Cloud run that created this run. This is " +
+		"detected automatically from the environment when the provider runs " +
+		"within Terraform Cloud and is empty otherwise.",
+	"source_run_id":       "The ID of the run that created this run.",
+	"source_organization": "The organization of the run that created this run.",
+	"source_workspace":    "The workspace of the run that created this run.",
+	"state_version_id_before": "The ID of the current state version of the " +
+		"workspace before the run was queued. Empty if the workspace had no state.",
+	"state_serial_before": "The serial of the current state version of the " +
+		"workspace before the run was queued.",
+	"state_version_id_after": "The ID of the current state version of the " +
+		"workspace after the run completed.",
+	"state_serial_after": "The serial of the current state version of the " +
+		"workspace after the run completed.",
+	"rollback_on_failure": "If true and the apply fails after all retries, a new " +
+		"run is queued using the configuration version of the last successfully " +
+		"applied run in the workspace. The original failure is still reported. " +
+		"The rollback run uses the `message` and `apply_comment` templates with " +
+		"`.Rollback` set and waits for manual confirmation if `manual_confirm` " +
+		"is true. This applies to creation only.",
 	"retry": "Whether or not to retry on plan or apply errors.",
 	"retry_attempts": "The number of retry attempts made for any errors during " +
-		"plan or apply. This applies to both creation and destruction.",
+		"plan or apply. This applies to both creation and destruction unless " +
+		"overridden by a `retry_plan`, `retry_apply`, or `retry_destroy` block.",
 	"retry_backoff_min": "The minimum seconds to wait between retry attempts.",
 	"retry_backoff_max": "The maximum seconds to wait between retry attempts. Retries " +
 		"are done using an exponential backoff, so this can be used to limit " +
 		"the maximum time between retries.",
+	"retry_plan": "Retry settings for errors during plan. If not set, the " +
+		"top-level retry settings are used.",
+	"retry_apply": "Retry settings for errors during apply. If not set, the " +
+		"top-level retry settings are used.",
+	"retry_destroy": "Retry settings for errors during a destroy run. If set, " +
+		"this takes precedence over `retry_plan` and `retry_apply` on destroy.",
 }
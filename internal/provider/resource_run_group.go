@@ -0,0 +1,202 @@
+package provider
+
+import (
+	"context"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+const (
+	failureModeFailFast = "fail_fast"
+	failureModeContinue = "continue"
+)
+
+// runGroupSharedKeys are the multispace_run settings that are also
+// available on multispace_run_group and apply to every member.
+var runGroupSharedKeys = []string{
+	"manual_confirm",
+	"message",
+	"apply_comment",
+	"metadata",
+	"rollback_on_failure",
+	"prevent_destroy_unless",
+	"temporary_variable",
+	"workspace_settings",
+	"retry",
+	"retry_attempts",
+	"retry_backoff_min",
+	"retry_backoff_max",
+	"retry_plan",
+	"retry_apply",
+	"retry_destroy",
+}
+
+func resourceRunGroup() *schema.Resource {
+	s := map[string]*schema.Schema{
+		"organization": {
+			Description: runGroupDescriptions["organization"],
+			Type:        schema.TypeString,
+			Required:    true,
+			ForceNew:    true,
+		},
+
+		"workspaces": {
+			Description: runGroupDescriptions["workspaces"],
+			Type:        schema.TypeList,
+			Required:    true,
+			ForceNew:    true,
+			MinItems:    1,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+		},
+
+		"max_parallelism": {
+			Description:  runGroupDescriptions["max_parallelism"],
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Default:      10,
+			ValidateFunc: validation.IntAtLeast(1),
+		},
+
+		"failure_mode": {
+			Description: runGroupDescriptions["failure_mode"],
+			Type:        schema.TypeString,
+			Optional:    true,
+			Default:     failureModeFailFast,
+			ValidateFunc: validation.StringInSlice([]string{
+				failureModeFailFast,
+				failureModeContinue,
+			}, false),
+		},
+
+		"runs": {
+			Description: runGroupDescriptions["runs"],
+			Type:        schema.TypeMap,
+			Computed:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+		},
+	}
+
+	// Share the run settings with multispace_run.
+	runSchema := resourceRun().Schema
+	for _, k := range runGroupSharedKeys {
+		s[k] = runSchema[k]
+	}
+
+	return &schema.Resource{
+		Description: "Concurrent workspace runs (create/destroy)",
+
+		CreateContext: resourceRunGroupCreate,
+		ReadContext:   resourceRunGroupRead,
+		UpdateContext: resourceRunGroupUpdate,
+		DeleteContext: resourceRunGroupDelete,
+
+		Schema: s,
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(30 * time.Minute),
+			Delete: schema.DefaultTimeout(30 * time.Minute),
+		},
+	}
+}
+
+func resourceRunGroupCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	runs, diags := doRunGroup(ctx, d, meta, false)
+	if diags.HasError() && len(runs) == 0 {
+		// Nothing applied so nothing was created and the next apply will
+		// run the group again.
+		return diags
+	}
+
+	// If some workspaces applied before a failure, we still save the group
+	// along with the error. Terraform then marks it tainted so it is
+	// replaced rather than forgotten with workspaces already applied.
+	d.SetId(resource.UniqueId())
+	if err := d.Set("runs", runs); err != nil {
+		return append(diags, diag.FromErr(err)...)
+	}
+
+	return diags
+}
+
+func resourceRunGroupRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// The runs we queued are historical so there is nothing to refresh.
+	return nil
+}
+
+func resourceRunGroupUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// Update we do nothing since we should have created during apply.
+	return nil
+}
+
+func resourceRunGroupDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	_, diags := doRunGroup(ctx, d, meta, true)
+	return diags
+}
+
+// doRunGroup executes doRun for every workspace in the group. It returns
+// the ID of the run queued for each workspace that completed successfully.
+func doRunGroup(
+	ctx context.Context,
+	d *schema.ResourceData,
+	meta interface{},
+	destroy bool,
+) (map[string]interface{}, diag.Diagnostics) {
+	client := meta.(*providerMeta).client
+	failFast := d.Get("failure_mode").(string) == failureModeFailFast
+
+	workspaces, diags := runGroupWorkspaces(d)
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	// Read all our settings up front since ResourceData isn't safe to
+	// use concurrently.
+	base := runConfigFromResourceData(d, meta.(*providerMeta), destroy)
+
+	// Check every workspace before destroying any of them.
+	if destroy {
+		if diags := checkDestroyGuards(ctx, client, base.AllowDestroy, base.DestroyGuard, base.Organization, workspaces); diags.HasError() {
+			return nil, diags
+		}
+	}
+
+	return doRunConcurrent(
+		ctx, client, base, workspaces,
+		d.Get("max_parallelism").(int),
+		failFast,
+	)
+}
+
+// runGroupWorkspaces returns the workspaces of the group. A workspace may
+// only be listed once since each would otherwise get concurrent runs.
+func runGroupWorkspaces(d *schema.ResourceData) ([]string, diag.Diagnostics) {
+	var workspaces []string
+	seen := map[string]struct{}{}
+	for _, v := range d.Get("workspaces").([]interface{}) {
+		workspace := v.(string)
+		if _, ok := seen[workspace]; ok {
+			return nil, diag.Errorf(
+				"Workspace %q is listed more than once in workspaces", workspace)
+		}
+
+		seen[workspace] = struct{}{}
+		workspaces = append(workspaces, workspace)
+	}
+
+	return workspaces, nil
+}
+
+var runGroupDescriptions = map[string]string{
+	"organization": "The name of the Terraform Cloud organization that owns the workspaces.",
+	"workspaces": "The names of the Terraform Cloud workspaces to execute. " +
+		"These are run concurrently and each may only be listed once.",
+	"max_parallelism": "The maximum number of workspaces to run at the same time.",
+	"failure_mode": "What to do when a workspace fails. `fail_fast` starts no " +
+		"new runs after the first failure but waits for runs in progress to " +
+		"finish. `continue` runs every workspace and reports all failures at the end.",
+	"runs": "The ID of the run queued for each workspace, keyed by workspace name.",
+}
@@ -0,0 +1,59 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestAccResourceRunGroup(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceRunGroup,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet("multispace_run_group.children", "runs.B"),
+					resource.TestCheckResourceAttrSet("multispace_run_group.children", "runs.C"),
+				),
+			},
+		},
+	})
+}
+
+func TestRunGroupWorkspaces(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourceRunGroup().Schema, map[string]interface{}{
+		"workspaces": []interface{}{"A", "B"},
+	})
+	workspaces, diags := runGroupWorkspaces(d)
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if !reflect.DeepEqual(workspaces, []string{"A", "B"}) {
+		t.Fatalf("unexpected workspaces: %v", workspaces)
+	}
+
+	d = schema.TestResourceDataRaw(t, resourceRunGroup().Schema, map[string]interface{}{
+		"workspaces": []interface{}{"A", "B", "A"},
+	})
+	if _, diags := runGroupWorkspaces(d); !diags.HasError() {
+		t.Fatal("expected an error for a duplicate workspace")
+	}
+}
+
+const testAccResourceRunGroup = `
+resource "multispace_run" "root" {
+  organization = "multispace-test"
+  workspace    = "root"
+}
+
+resource "multispace_run_group" "children" {
+  organization    = "multispace-test"
+  workspaces      = ["B", "C"]
+  max_parallelism = 1
+  depends_on      = [multispace_run.root]
+}
+`
@@ -43,3 +43,37 @@ resource "multispace_run" "C" {
   depends_on   = [multispace_run.A]
 }
 `
+
+func TestAccResourceRun_temporaryVariable(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceRun_temporaryVariable,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet("multispace_run.root", "id"),
+				),
+			},
+		},
+	})
+}
+
+const testAccResourceRun_temporaryVariable = `
+resource "multispace_run" "root" {
+  organization = "multispace-test"
+  workspace    = "root"
+
+  temporary_variable {
+    key   = "bootstrap"
+    value = "true"
+  }
+
+  temporary_variable {
+    key       = "MULTISPACE_TEST_SECRET"
+    value     = "hunter2"
+    category  = "env"
+    sensitive = true
+  }
+}
+`
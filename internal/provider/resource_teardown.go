@@ -0,0 +1,223 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tfe "github.com/hashicorp/go-tfe"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// teardownSharedKeys are the multispace_run settings that apply to the
+// destroy runs of multispace_teardown.
+var teardownSharedKeys = []string{
+	"message",
+	"apply_comment",
+	"metadata",
+	"retry",
+	"retry_attempts",
+	"retry_backoff_min",
+	"retry_backoff_max",
+	"retry_plan",
+	"retry_apply",
+	"retry_destroy",
+	"prevent_destroy_unless",
+	"temporary_variable",
+	"workspace_settings",
+}
+
+func resourceTeardown() *schema.Resource {
+	s := map[string]*schema.Schema{
+		"organization": {
+			Description: teardownDescriptions["organization"],
+			Type:        schema.TypeString,
+			Required:    true,
+			ForceNew:    true,
+		},
+
+		"root_workspace": {
+			Description: teardownDescriptions["root_workspace"],
+			Type:        schema.TypeString,
+			Required:    true,
+			ForceNew:    true,
+		},
+
+		"max_parallelism": {
+			Description:  teardownDescriptions["max_parallelism"],
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Default:      10,
+			ValidateFunc: validation.IntAtLeast(1),
+		},
+
+		"workspaces": {
+			Description: teardownDescriptions["workspaces"],
+			Type:        schema.TypeList,
+			Computed:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+		},
+	}
+
+	// Share the run settings with multispace_run.
+	runSchema := resourceRun().Schema
+	for _, k := range teardownSharedKeys {
+		s[k] = runSchema[k]
+	}
+
+	return &schema.Resource{
+		Description: "Destroy every workspace reachable through run triggers (destroy)",
+
+		CreateContext: resourceTeardownCreate,
+		ReadContext:   resourceTeardownRead,
+		UpdateContext: resourceTeardownUpdate,
+		DeleteContext: resourceTeardownDelete,
+
+		Schema: s,
+
+		Timeouts: &schema.ResourceTimeout{
+			Delete: schema.DefaultTimeout(60 * time.Minute),
+		},
+	}
+}
+
+func resourceTeardownCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*providerMeta).client
+
+	// We discover the graph on create so that cycles are reported early
+	// and the workspaces are visible, but we don't run anything.
+	_, _, order, err := discoverWorkspaceLevels(ctx, client,
+		d.Get("organization").(string),
+		d.Get("root_workspace").(string),
+		true,
+	)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(resource.UniqueId())
+	if err := d.Set("workspaces", order); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
+
+func resourceTeardownRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// The graph is discovered again on destroy so there is nothing to refresh.
+	return nil
+}
+
+func resourceTeardownUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// Settings only matter on destroy and are read from state then.
+	return nil
+}
+
+func resourceTeardownDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*providerMeta).client
+	org := d.Get("organization").(string)
+	root := d.Get("root_workspace").(string)
+
+	graph, levels, order, err := discoverWorkspaceLevels(ctx, client, org, root, true)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	logger := logger.Named("teardown").With(
+		"organization", org,
+		"root_workspace", root,
+	)
+	logger.Info("discovered workspace graph", "workspaces", order)
+
+	// Check every workspace before destroying any of them.
+	base := runConfigFromResourceData(d, meta.(*providerMeta), true)
+	if diags := checkDestroyGuards(ctx, client, base.AllowDestroy, base.DestroyGuard, org, order); diags.HasError() {
+		return diags
+	}
+
+	parallelism := d.Get("max_parallelism").(int)
+	for _, level := range levels {
+		runs, diags := doRunConcurrent(ctx, client, base, level, parallelism, true)
+		if diags.HasError() {
+			return diags
+		}
+
+		// Each destroy is an apply that queues runs in the workspaces it
+		// triggers, which were already destroyed. Those runs would plan to
+		// recreate everything.
+		if err := discardRunsTriggeredBy(ctx, client, org, graph, runs); err != nil {
+			return diag.Errorf("Failed to discard triggered runs: %s", err)
+		}
+
+		// Verify that the level is really empty before destroying the
+		// workspaces it depends on.
+		for _, workspace := range level {
+			if diags := verifyEmptyState(ctx, client, org, workspace); diags.HasError() {
+				return diags
+			}
+		}
+	}
+
+	return nil
+}
+
+// verifyEmptyState returns an error if the workspace state still has any
+// managed resources.
+func verifyEmptyState(
+	ctx context.Context,
+	client *tfe.Client,
+	org string,
+	workspace string,
+) diag.Diagnostics {
+	ws, err := client.Workspaces.Read(ctx, org, workspace)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	sv, err := currentStateVersion(ctx, client, ws.ID)
+	if err != nil {
+		return diag.Errorf("Failed to retrieve current state version: %s", err)
+	}
+	if sv == nil {
+		return nil
+	}
+
+	state, err := readStateFile(ctx, client, sv)
+	if err != nil {
+		return diag.Errorf("Failed to read state of workspace %q: %s", workspace, err)
+	}
+
+	managed := state.managedResources()
+	if len(managed) == 0 {
+		return nil
+	}
+
+	var addrs []string
+	for _, r := range managed {
+		addrs = append(addrs, r.address())
+	}
+
+	return diag.Diagnostics{{
+		Severity: diag.Error,
+		Summary:  fmt.Sprintf("Workspace %q is not empty after destroy", workspace),
+		Detail: fmt.Sprintf(
+			"The state still has %d resources, so the workspaces it depends "+
+				"on were not destroyed. Remaining resources:\n\n  %s",
+			len(addrs), strings.Join(addrs, "\n  ")),
+	}}
+}
+
+var teardownDescriptions = map[string]string{
+	"organization": "The name of the Terraform Cloud organization that owns the workspaces.",
+	"root_workspace": "The name of the workspace to start from. Every workspace " +
+		"reachable from this workspace through run triggers is destroyed.",
+	"max_parallelism": "The maximum number of workspaces to destroy at the same time. " +
+		"Only workspaces that don't depend on each other are destroyed at the same time.",
+	"workspaces": "The workspaces reachable from the root workspace when this " +
+		"resource was created, in topological order. The graph is discovered " +
+		"again on destroy.",
+}
@@ -0,0 +1,49 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestTeardownRunConfig(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourceTeardown().Schema, map[string]interface{}{
+		"organization":   "multispace-test",
+		"root_workspace": "root",
+		"message":        "teardown",
+	})
+
+	cfg := runConfigFromResourceData(d, &providerMeta{allowDestroy: true}, true)
+	if cfg.Organization != "multispace-test" || cfg.Message != "teardown" || !cfg.Destroy || !cfg.AllowDestroy {
+		t.Fatalf("bad: %#v", cfg)
+	}
+
+	// Teardown has no manual_confirm or rollback_on_failure.
+	if cfg.ManualConfirm || cfg.RollbackOnFailure {
+		t.Fatalf("bad: %#v", cfg)
+	}
+}
+
+func TestAccResourceTeardown(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceTeardown,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("multispace_teardown.env", "workspaces.0", "root"),
+				),
+			},
+		},
+	})
+}
+
+const testAccResourceTeardown = `
+resource "multispace_teardown" "env" {
+  organization    = "multispace-test"
+  root_workspace  = "root"
+  max_parallelism = 2
+}
+`
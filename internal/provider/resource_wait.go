@@ -0,0 +1,250 @@
+package provider
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	tfe "github.com/hashicorp/go-tfe"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func resourceWait() *schema.Resource {
+	return &schema.Resource{
+		Description: "Wait for an externally queued run (create)",
+
+		CreateContext: resourceWaitCreate,
+		ReadContext:   resourceWaitRead,
+		DeleteContext: resourceWaitDelete,
+
+		Schema: map[string]*schema.Schema{
+			"organization": {
+				Description: waitDescriptions["organization"],
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+			},
+
+			"workspace": {
+				Description: waitDescriptions["workspace"],
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+			},
+
+			"commit_sha": {
+				Description: waitDescriptions["commit_sha"],
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+			},
+
+			"created_after": {
+				Description:  waitDescriptions["created_after"],
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.IsRFC3339Time,
+			},
+
+			"triggers": {
+				Description: waitDescriptions["triggers"],
+				Type:        schema.TypeMap,
+				Optional:    true,
+				ForceNew:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+
+			"run_id": {
+				Description: waitDescriptions["run_id"],
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+
+			"status": {
+				Description: waitDescriptions["status"],
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(30 * time.Minute),
+		},
+	}
+}
+
+func resourceWaitCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*providerMeta).client
+	org := d.Get("organization").(string)
+	workspace := d.Get("workspace").(string)
+	sha := d.Get("commit_sha").(string)
+
+	var after time.Time
+	if v, ok := d.GetOk("created_after"); ok {
+		// Validated by the schema.
+		after, _ = time.Parse(time.RFC3339, v.(string))
+	}
+
+	logger := logger.Named("wait").With(
+		"organization", org,
+		"workspace", workspace,
+		"commit_sha", sha,
+	)
+	ctx = hclog.WithContext(ctx, logger)
+
+	ws, err := client.Workspaces.Read(ctx, org, workspace)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	// Wait for a matching run to show up since the run may not have been
+	// queued yet, for example if the VCS webhook is slow.
+	var run *tfe.Run
+	for {
+		run, err = findWaitRun(ctx, client, ws.ID, sha, after)
+		if err != nil {
+			return diag.Errorf("Failed to find run: %s", err)
+		}
+		if run != nil {
+			break
+		}
+
+		logger.Debug("no matching run yet")
+		select {
+		case <-ctx.Done():
+			return diag.Errorf(
+				"Timed out waiting for a matching run in workspace %q: %s",
+				workspace, ctx.Err())
+		case <-time.After(runPollInterval):
+		}
+	}
+
+	logger = logger.With("run_id", run.ID)
+	ctx = hclog.WithContext(ctx, logger)
+	logger.Info("found run, waiting for it to finish", "status", run.Status)
+
+	run, diags := waitForRun(ctx, client, org, run, ws, false, []tfe.RunStatus{
+		tfe.RunApplied,
+		tfe.RunPlannedAndFinished,
+		tfe.RunErrored,
+		tfe.RunCanceled,
+		tfe.RunDiscarded,
+	}, []tfe.RunStatus{
+		tfe.RunPending,
+		tfe.RunPlanQueued,
+		tfe.RunPlanning,
+		tfe.RunPlanned,
+		tfe.RunCostEstimating,
+		tfe.RunCostEstimated,
+		tfe.RunPolicyChecking,
+		tfe.RunPolicyChecked,
+		tfe.RunPolicyOverride,
+		tfe.RunPolicySoftFailed,
+		tfe.RunConfirmed,
+		tfe.RunApplyQueued,
+		tfe.RunApplying,
+	})
+	if diags != nil {
+		return diags
+	}
+
+	if run.Status != tfe.RunApplied && run.Status != tfe.RunPlannedAndFinished {
+		return diag.Errorf(
+			"Run %q finished with status %q, expected applied. Please open "+
+				"the web UI to view the run.",
+			run.ID, run.Status)
+	}
+
+	d.SetId(run.ID)
+	if err := d.Set("run_id", run.ID); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("status", string(run.Status)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
+
+func resourceWaitRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// The run we waited for is historical so there is nothing to refresh.
+	return nil
+}
+
+func resourceWaitDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// We never queued anything so there is nothing to destroy.
+	return nil
+}
+
+// findWaitRun returns the latest non-destroy run in the workspace created
+// after the given time and, if sha is set, for that commit. This returns
+// nil with no error if there is no such run.
+func findWaitRun(
+	ctx context.Context,
+	client *tfe.Client,
+	workspaceID string,
+	sha string,
+	after time.Time,
+) (*tfe.Run, error) {
+	// We only look at the most recent page since we want the latest run.
+	rl, err := client.Runs.List(ctx, workspaceID, tfe.RunListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range rl.Items {
+		// Runs are listed newest first so nothing after this can match.
+		if r.CreatedAt.Before(after) {
+			break
+		}
+		if r.IsDestroy {
+			continue
+		}
+		if sha == "" {
+			return r, nil
+		}
+		if r.ConfigurationVersion == nil {
+			continue
+		}
+
+		cv, err := client.ConfigurationVersions.ReadWithOptions(
+			ctx, r.ConfigurationVersion.ID, &tfe.ConfigurationVersionReadOptions{
+				Include: "ingress_attributes",
+			})
+		if err != nil {
+			return nil, err
+		}
+		if cv.IngressAttributes != nil && commitMatches(cv.IngressAttributes.CommitSHA, sha) {
+			return r, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// commitMatches returns true if the commit SHA matches the wanted SHA,
+// which may be abbreviated.
+func commitMatches(actual, want string) bool {
+	if actual == "" || want == "" {
+		return false
+	}
+
+	return strings.HasPrefix(strings.ToLower(actual), strings.ToLower(want))
+}
+
+var waitDescriptions = map[string]string{
+	"organization": "The name of the Terraform Cloud organization that owns the workspace.",
+	"workspace":    "The name of the Terraform Cloud workspace to wait for.",
+	"commit_sha": "Only wait for a run of this VCS commit. This may be an " +
+		"abbreviated SHA.",
+	"created_after": "Only wait for a run created after this time, in RFC 3339 format.",
+	"triggers": "Arbitrary values that cause a new wait when they change, " +
+		"such as a commit SHA from another resource.",
+	"run_id": "The ID of the run that was waited for.",
+	"status": "The final status of the run. This is `applied`, or " +
+		"`planned_and_finished` if the plan had no changes.",
+}
@@ -0,0 +1,61 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestCommitMatches(t *testing.T) {
+	cases := []struct {
+		Actual   string
+		Want     string
+		Expected bool
+	}{
+		{"3f2a9c1d8e", "3f2a9c1d8e", true},
+		{"3f2a9c1d8e", "3f2a9c1", true},
+		{"3f2a9c1d8e", "3F2A9C1", true},
+		{"3f2a9c1d8e", "4f2a9c1", false},
+		{"3f2a9c1d8e", "", false},
+		{"", "3f2a9c1", false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Actual+"/"+tc.Want, func(t *testing.T) {
+			if actual := commitMatches(tc.Actual, tc.Want); actual != tc.Expected {
+				t.Fatalf("expected %v, got %v", tc.Expected, actual)
+			}
+		})
+	}
+}
+
+func TestAccResourceWait(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceWait,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair(
+						"multispace_wait.root", "run_id",
+						"multispace_run.root", "id",
+					),
+				),
+			},
+		},
+	})
+}
+
+const testAccResourceWait = `
+resource "multispace_run" "root" {
+  organization = "multispace-test"
+  workspace    = "root"
+}
+
+resource "multispace_wait" "root" {
+  organization = "multispace-test"
+  workspace    = "root"
+  depends_on   = [multispace_run.root]
+}
+`
@@ -0,0 +1,135 @@
+package provider
+
+import (
+	"context"
+	"strings"
+
+	tfe "github.com/hashicorp/go-tfe"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const defaultLockReason = "Locked by terraform-provider-multispace"
+
+func resourceWorkspaceLock() *schema.Resource {
+	return &schema.Resource{
+		Description: "Workspace lock (lock/unlock)",
+
+		CreateContext: resourceWorkspaceLockCreate,
+		ReadContext:   resourceWorkspaceLockRead,
+		DeleteContext: resourceWorkspaceLockDelete,
+
+		Schema: map[string]*schema.Schema{
+			"organization": {
+				Description: workspaceLockDescriptions["organization"],
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+			},
+
+			"workspace": {
+				Description: workspaceLockDescriptions["workspace"],
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+			},
+
+			"reason": {
+				Description: workspaceLockDescriptions["reason"],
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     defaultLockReason,
+			},
+		},
+	}
+}
+
+func resourceWorkspaceLockCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*providerMeta).client
+	org := d.Get("organization").(string)
+	workspace := d.Get("workspace").(string)
+	reason := d.Get("reason").(string)
+
+	ws, err := client.Workspaces.Read(ctx, org, workspace)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	logger.Named("lock").Info("locking workspace",
+		"organization", org,
+		"workspace", workspace,
+	)
+	_, err = client.Workspaces.Lock(ctx, ws.ID, tfe.WorkspaceLockOptions{
+		Reason: tfe.String(reason),
+	})
+	if err != nil {
+		if err == tfe.ErrWorkspaceLocked {
+			return diag.Errorf(
+				"Workspace %q is already locked. Unlock it or wait for the "+
+					"current lock holder to finish before locking it.",
+				workspace)
+		}
+
+		return diag.Errorf("Failed to lock workspace %q: %s", workspace, err)
+	}
+
+	// The ID is the workspace ID since there is only ever one lock.
+	d.SetId(ws.ID)
+	holdWorkspaceLock(ws.ID, reason)
+
+	return nil
+}
+
+func resourceWorkspaceLockRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*providerMeta).client
+	id := d.Id()
+
+	// If the workspace is gone or someone else unlocked it, then we no
+	// longer hold the lock.
+	ws, err := client.Workspaces.ReadByID(ctx, id)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			releaseWorkspaceLock(id)
+			d.SetId("")
+			return nil
+		}
+
+		return diag.FromErr(err)
+	}
+	if !ws.Locked {
+		releaseWorkspaceLock(id)
+		d.SetId("")
+		return nil
+	}
+
+	// We remember the lock so that runs queued in later operations of
+	// this provider can hand the lock off to themselves.
+	holdWorkspaceLock(id, d.Get("reason").(string))
+
+	return nil
+}
+
+func resourceWorkspaceLockDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*providerMeta).client
+	id := d.Id()
+
+	logger.Named("lock").Info("unlocking workspace",
+		"organization", d.Get("organization").(string),
+		"workspace", d.Get("workspace").(string),
+	)
+
+	// Release first so no runs try to take the lock back after this.
+	releaseWorkspaceLock(id)
+	if _, err := client.Workspaces.Unlock(ctx, id); err != nil && err != tfe.ErrWorkspaceNotLocked {
+		return diag.Errorf("Failed to unlock workspace: %s", err)
+	}
+
+	return nil
+}
+
+var workspaceLockDescriptions = map[string]string{
+	"organization": "The name of the Terraform Cloud organization that owns the workspace.",
+	"workspace":    "The name of the Terraform Cloud workspace to lock.",
+	"reason":       "The reason for the lock, shown in the Terraform Cloud UI.",
+}
@@ -0,0 +1,37 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccResourceWorkspaceLock(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceWorkspaceLock,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet("multispace_workspace_lock.lock", "id"),
+					resource.TestCheckResourceAttrSet("multispace_run.root", "id"),
+				),
+			},
+		},
+	})
+}
+
+const testAccResourceWorkspaceLock = `
+resource "multispace_workspace_lock" "lock" {
+  organization = "multispace-test"
+  workspace    = "root"
+  reason       = "acceptance test"
+}
+
+resource "multispace_run" "root" {
+  organization = "multispace-test"
+  workspace    = "root"
+  depends_on   = [multispace_workspace_lock.lock]
+}
+`
@@ -0,0 +1,138 @@
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// runPhase is the phase of a run that a failure occurred in. This is used
+// to select the retry policy to apply.
+type runPhase string
+
+const (
+	runPhasePlan  runPhase = "plan"
+	runPhaseApply runPhase = "apply"
+)
+
+// The defaults of the retry settings, shared by the top-level settings and
+// the retry_* blocks. Backoffs are in seconds.
+const (
+	defaultRetryAttempts   = 3
+	defaultRetryBackoffMin = 1
+	defaultRetryBackoffMax = 30
+)
+
+// retryPolicy is the retry configuration used for a single phase of a run.
+type retryPolicy struct {
+	Enabled    bool
+	Attempts   int
+	BackoffMin int // seconds
+	BackoffMax int // seconds
+}
+
+// retryPolicySchema is the schema for the nested retry_* blocks that
+// override the top-level retry settings.
+func retryPolicySchema(desc string) *schema.Schema {
+	return &schema.Schema{
+		Description: desc,
+		Type:        schema.TypeList,
+		Optional:    true,
+		MaxItems:    1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"enabled": {
+					Description: retryPolicyDescriptions["enabled"],
+					Type:        schema.TypeBool,
+					Optional:    true,
+					Default:     true,
+				},
+
+				"attempts": {
+					Description: retryPolicyDescriptions["attempts"],
+					Type:        schema.TypeInt,
+					Optional:    true,
+					Default:     defaultRetryAttempts,
+				},
+
+				"backoff_min": {
+					Description: retryPolicyDescriptions["backoff_min"],
+					Type:        schema.TypeInt,
+					Optional:    true,
+					Default:     defaultRetryBackoffMin,
+				},
+
+				"backoff_max": {
+					Description: retryPolicyDescriptions["backoff_max"],
+					Type:        schema.TypeInt,
+					Optional:    true,
+					Default:     defaultRetryBackoffMax,
+				},
+			},
+		},
+	}
+}
+
+// retryPolicies returns the retry policy for each phase of the run. For
+// destroys, a retry_destroy block takes precedence over the per-phase blocks.
+// Any phase without a block uses the top-level retry settings.
+func retryPolicies(d *schema.ResourceData, destroy bool) map[runPhase]retryPolicy {
+	def := retryPolicy{
+		Enabled:    d.Get("retry").(bool),
+		Attempts:   d.Get("retry_attempts").(int),
+		BackoffMin: d.Get("retry_backoff_min").(int),
+		BackoffMax: d.Get("retry_backoff_max").(int),
+	}
+
+	result := map[runPhase]retryPolicy{
+		runPhasePlan:  readRetryPolicy(d, "retry_plan", def),
+		runPhaseApply: readRetryPolicy(d, "retry_apply", def),
+	}
+	if destroy {
+		if _, ok := d.GetOk("retry_destroy"); ok {
+			p := readRetryPolicy(d, "retry_destroy", def)
+			result[runPhasePlan] = p
+			result[runPhaseApply] = p
+		}
+	}
+
+	return result
+}
+
+// readRetryPolicy reads the retry block at key, returning def if the
+// block is not set.
+func readRetryPolicy(d *schema.ResourceData, key string, def retryPolicy) retryPolicy {
+	raw, ok := d.GetOk(key)
+	if !ok {
+		return def
+	}
+
+	list := raw.([]interface{})
+	if len(list) == 0 {
+		return def
+	}
+
+	// An empty block has no attributes set, which means all the defaults.
+	m, ok := list[0].(map[string]interface{})
+	if !ok {
+		return retryPolicy{
+			Enabled:    true,
+			Attempts:   defaultRetryAttempts,
+			BackoffMin: defaultRetryBackoffMin,
+			BackoffMax: defaultRetryBackoffMax,
+		}
+	}
+
+	return retryPolicy{
+		Enabled:    m["enabled"].(bool),
+		Attempts:   m["attempts"].(int),
+		BackoffMin: m["backoff_min"].(int),
+		BackoffMax: m["backoff_max"].(int),
+	}
+}
+
+var retryPolicyDescriptions = map[string]string{
+	"enabled":     "Whether or not to retry on errors during this phase.",
+	"attempts":    "The number of retry attempts made for errors during this phase.",
+	"backoff_min": "The minimum seconds to wait between retry attempts.",
+	"backoff_max": "The maximum seconds to wait between retry attempts. Retries " +
+		"are done using an exponential backoff.",
+}
@@ -0,0 +1,88 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestRetryPolicies(t *testing.T) {
+	cases := []struct {
+		Name    string
+		Raw     map[string]interface{}
+		Destroy bool
+		Plan    retryPolicy
+		Apply   retryPolicy
+	}{
+		{
+			"defaults",
+			map[string]interface{}{},
+			false,
+			retryPolicy{Enabled: true, Attempts: 3, BackoffMin: 1, BackoffMax: 30},
+			retryPolicy{Enabled: true, Attempts: 3, BackoffMin: 1, BackoffMax: 30},
+		},
+
+		{
+			"top-level settings",
+			map[string]interface{}{
+				"retry":          false,
+				"retry_attempts": 5,
+			},
+			false,
+			retryPolicy{Enabled: false, Attempts: 5, BackoffMin: 1, BackoffMax: 30},
+			retryPolicy{Enabled: false, Attempts: 5, BackoffMin: 1, BackoffMax: 30},
+		},
+
+		{
+			"apply override",
+			map[string]interface{}{
+				"retry_apply": []interface{}{
+					map[string]interface{}{"enabled": false},
+				},
+			},
+			false,
+			retryPolicy{Enabled: true, Attempts: 3, BackoffMin: 1, BackoffMax: 30},
+			retryPolicy{Enabled: false, Attempts: 3, BackoffMin: 1, BackoffMax: 30},
+		},
+
+		{
+			"destroy ignored on create",
+			map[string]interface{}{
+				"retry_destroy": []interface{}{
+					map[string]interface{}{"attempts": 10},
+				},
+			},
+			false,
+			retryPolicy{Enabled: true, Attempts: 3, BackoffMin: 1, BackoffMax: 30},
+			retryPolicy{Enabled: true, Attempts: 3, BackoffMin: 1, BackoffMax: 30},
+		},
+
+		{
+			"destroy overrides phases",
+			map[string]interface{}{
+				"retry_apply": []interface{}{
+					map[string]interface{}{"enabled": false},
+				},
+				"retry_destroy": []interface{}{
+					map[string]interface{}{"attempts": 10},
+				},
+			},
+			true,
+			retryPolicy{Enabled: true, Attempts: 10, BackoffMin: 1, BackoffMax: 30},
+			retryPolicy{Enabled: true, Attempts: 10, BackoffMin: 1, BackoffMax: 30},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, resourceRun().Schema, tc.Raw)
+			actual := retryPolicies(d, tc.Destroy)
+			if actual[runPhasePlan] != tc.Plan {
+				t.Fatalf("bad plan policy: %#v", actual[runPhasePlan])
+			}
+			if actual[runPhaseApply] != tc.Apply {
+				t.Fatalf("bad apply policy: %#v", actual[runPhaseApply])
+			}
+		})
+	}
+}
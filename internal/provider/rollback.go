@@ -0,0 +1,93 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/go-hclog"
+	tfe "github.com/hashicorp/go-tfe"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+// rollbackSearchPages is how many pages of runs, newest first, are
+// searched for the last applied configuration version.
+const rollbackSearchPages = 5
+
+// lastAppliedConfigurationVersion returns the configuration version of the
+// most recent successfully applied, non-destroy run in the workspace. If
+// no such run is found in the last rollbackSearchPages pages of runs, this
+// returns nil with no error.
+func lastAppliedConfigurationVersion(
+	ctx context.Context,
+	client *tfe.Client,
+	workspaceID string,
+) (*tfe.ConfigurationVersion, error) {
+	run, err := findLatestRun(ctx, client, workspaceID, runFilter{
+		Status:    string(tfe.RunApplied),
+		IsDestroy: tfe.Bool(false),
+		MaxPages:  rollbackSearchPages,
+	})
+	if err != nil || run == nil {
+		return nil, err
+	}
+
+	return run.ConfigurationVersion, nil
+}
+
+// rollbackRun queues a run with the given configuration version after an
+// apply failed and waits for it to apply. The diagnostics of the original
+// failure are always returned, along with the outcome of the rollback.
+func rollbackRun(
+	ctx context.Context,
+	client *tfe.Client,
+	cfg *runConfig,
+	cv *tfe.ConfigurationVersion,
+	handoff *lockHandoff,
+	failed diag.Diagnostics,
+) diag.Diagnostics {
+	if !cfg.RollbackOnFailure || cfg.Destroy {
+		return failed
+	}
+
+	logger := hclog.FromContext(ctx).Named("rollback")
+	if cv == nil {
+		logger.Warn("no previously applied configuration version, not rolling back")
+		return append(failed, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  "Rollback skipped",
+			Detail: fmt.Sprintf(
+				"Workspace %q has no previously applied configuration version "+
+					"to roll back to.", cfg.Workspace),
+		})
+	}
+
+	logger = logger.With("configuration_version_id", cv.ID)
+	ctx = hclog.WithContext(ctx, logger)
+
+	// The rollback never retries since we're already recovering from a
+	// failure, but it still waits for manual confirmation if our run would
+	// have. The resource keeps tracking our original run.
+	rollback := *cfg
+	rollback.ConfigurationVersion = cv
+	rollback.RollbackOnFailure = false
+	rollback.Retry = nil
+	rollback.SetID = nil
+	result, diags := doRunAttempts(ctx, client, &rollback, handoff)
+	if diags.HasError() {
+		for i := range diags {
+			diags[i].Summary = "Rollback failed: " + diags[i].Summary
+		}
+
+		return append(failed, diags...)
+	}
+
+	logger.Info("rollback applied", "rollback_run_id", result.RunID)
+	return append(failed, diag.Diagnostic{
+		Severity: diag.Warning,
+		Summary:  "Rolled back to the previous configuration",
+		Detail: fmt.Sprintf(
+			"Run %q applied configuration version %q, which was the last "+
+				"successfully applied configuration in workspace %q.",
+			result.RunID, cv.ID, cfg.Workspace),
+	})
+}
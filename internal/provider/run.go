@@ -0,0 +1,597 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	tfe "github.com/hashicorp/go-tfe"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+// runConfig is the configuration for a single run lifecycle executed
+// by doRun.
+type runConfig struct {
+	Organization  string
+	Workspace     string
+	Destroy       bool
+	ManualConfirm bool
+	Message       string
+	ApplyComment  string
+	Metadata      map[string]string
+	Retry         map[runPhase]retryPolicy
+
+	// RollbackOnFailure queues a run of the last successfully applied
+	// configuration version if the apply fails and retries are exhausted.
+	RollbackOnFailure bool
+
+	// ConfigurationVersion, if non-nil, is queued instead of the latest
+	// configuration version of the workspace. This is how we roll back so
+	// it is also the `.Rollback` of the message templates.
+	ConfigurationVersion *tfe.ConfigurationVersion
+
+	// Settings, if non-nil, are workspace settings changed only for the
+	// duration of the run.
+	Settings *workspaceSettings
+
+	// Variables are workspace variables set only for the duration of
+	// the run.
+	Variables []temporaryVariable
+
+	// AllowDestroy is false if the provider refuses every destroy run.
+	AllowDestroy bool
+
+	// API, if non-nil, is used to hand workspace locks held by us off to
+	// our run.
+	API *apiClient
+
+	// DestroyGuard, if non-nil, refuses destroy runs unless the workspace
+	// allows them.
+	DestroyGuard *destroyGuard
+
+	// SetID, if non-nil, is called with the ID of the run as soon as it is
+	// queued and with "" if the run fails. This lets resources track the
+	// run even if we fail partway through.
+	SetID func(string)
+}
+
+// runResult is the result of doRun. Fields are populated as the run
+// progresses so a partial result may be returned along with an error.
+type runResult struct {
+	// RunID is the ID of the last run queued.
+	RunID string
+
+	// Source is the parent Terraform Cloud run, if any.
+	Source runSource
+
+	// StateBefore and StateAfter are the current state versions of the
+	// workspace before the run was queued and after it completed. These
+	// are nil if the workspace has no state.
+	StateBefore *tfe.StateVersion
+	StateAfter  *tfe.StateVersion
+}
+
+// doRun queues a run in a workspace and takes it through plan and apply,
+// retrying according to the retry policies. This is the shared lifecycle
+// of everything in this provider that runs a workspace.
+func doRun(
+	ctx context.Context,
+	client *tfe.Client,
+	cfg *runConfig,
+) (*runResult, diag.Diagnostics) {
+	if cfg.Destroy {
+		if diags := checkDestroyGuard(ctx, client, cfg.AllowDestroy, cfg.DestroyGuard, cfg.Organization, cfg.Workspace); diags.HasError() {
+			return nil, diags
+		}
+	}
+
+	org, workspace := cfg.Organization, cfg.Workspace
+
+	// If we hold a lock on the workspace, our run is queued while the
+	// workspace is still locked and the lock is handed off to it.
+	handoff, diags := handoffWorkspaceLock(ctx, client, cfg.API, org, workspace)
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	// Each step that prepares the workspace for our run returns a function
+	// that undoes it. These are called in reverse order once we're done,
+	// whether or not the run succeeded. The lock is taken back last so
+	// that everything else is restored before.
+	undo := []func() diag.Diagnostics{handoff.relock}
+	finish := func(diags diag.Diagnostics) diag.Diagnostics {
+		for i := len(undo) - 1; i >= 0; i-- {
+			diags = append(diags, undo[i]()...)
+		}
+
+		return diags
+	}
+
+	for _, prepare := range []func() (func() diag.Diagnostics, diag.Diagnostics){
+		func() (func() diag.Diagnostics, diag.Diagnostics) {
+			return setWorkspaceSettings(ctx, client, org, workspace, cfg.Settings)
+		},
+
+		func() (func() diag.Diagnostics, diag.Diagnostics) {
+			return setTemporaryVariables(ctx, client, org, workspace, cfg.Variables)
+		},
+	} {
+		f, prepareDiags := prepare()
+		diags = append(diags, prepareDiags...)
+		if prepareDiags.HasError() {
+			return nil, finish(diags)
+		}
+		if f != nil {
+			undo = append(undo, f)
+		}
+	}
+
+	result, runDiags := doRunAttempts(ctx, client, cfg, handoff)
+	return result, finish(append(diags, runDiags...))
+}
+
+// doRunAttempts is the implementation of doRun once the workspace has
+// been prepared for our run.
+func doRunAttempts(
+	ctx context.Context,
+	client *tfe.Client,
+	cfg *runConfig,
+	handoff *lockHandoff,
+) (*runResult, diag.Diagnostics) {
+	setId := func(v string) {
+		if cfg.SetID != nil {
+			cfg.SetID(v)
+		}
+	}
+
+	org := cfg.Organization
+	workspace := cfg.Workspace
+	destroy := cfg.Destroy
+	result := &runResult{}
+
+	// Each phase has its own retry policy and its own count of failures.
+	policies := cfg.Retry
+	if policies == nil {
+		policies = map[runPhase]retryPolicy{}
+	}
+	retryFailures := map[runPhase]int{}
+	var diags diag.Diagnostics
+	var retryPhase runPhase
+	var rollbackCV *tfe.ConfigurationVersion
+	attempt := 0
+
+RETRY:
+	attempt++
+	if retryPhase != "" {
+		policy := policies[retryPhase]
+		retryFailures[retryPhase]++
+		if retryFailures[retryPhase] >= policy.Attempts {
+			diags := diag.Errorf(
+				"Maximum retry attempts %d reached during %s. Please see the web UI "+
+					"to see any errors during plan or apply.",
+				policy.Attempts, retryPhase,
+			)
+			if retryPhase == runPhaseApply {
+				diags = rollbackRun(ctx, client, cfg, rollbackCV, handoff, diags)
+			}
+
+			return result, diags
+		}
+
+		// If we're retrying, then perform the backoff. The policy is in
+		// seconds but backoff works in milliseconds.
+		select {
+		case <-ctx.Done():
+			return result, diag.FromErr(ctx.Err())
+		case <-time.After(backoff(
+			float64(policy.BackoffMin*1000),
+			float64(policy.BackoffMax*1000),
+			retryFailures[retryPhase]+1,
+		)):
+		}
+	}
+
+	// Setup our logger with the context of this run. This is passed down
+	// via the context so everything we call logs with the same fields.
+	logger := logger.Named("run").With(
+		"organization", org,
+		"workspace", workspace,
+		"destroy", destroy,
+		"attempt", attempt,
+	)
+	ctx = hclog.WithContext(ctx, logger)
+
+	// Get our workspace because we need it to queue a plan
+	ws, err := client.Workspaces.Read(ctx, org, workspace)
+	if err != nil {
+		return result, diag.FromErr(err)
+	}
+
+	// recordState records the current state version of the workspace.
+	recordState := func() (*tfe.StateVersion, diag.Diagnostics) {
+		sv, err := currentStateVersion(ctx, client, ws.ID)
+		if err != nil {
+			return nil, diag.Errorf("Failed to retrieve current state version: %s", err)
+		}
+		if sv != nil {
+			logger.Debug("recorded state version",
+				"state_version_id", sv.ID, "serial", sv.Serial)
+		}
+
+		return sv, nil
+	}
+
+	// Record the state before we do anything. We only do this on the first
+	// attempt so that the changes of failed attempts are still visible.
+	if attempt == 1 {
+		result.StateBefore, diags = recordState()
+		if diags != nil {
+			return result, diags
+		}
+
+		// Remember the configuration to roll back to before our run
+		// becomes the latest one.
+		if cfg.RollbackOnFailure && !destroy {
+			rollbackCV, err = lastAppliedConfigurationVersion(ctx, client, ws.ID)
+			if err != nil {
+				return result, diag.Errorf(
+					"Failed to retrieve last applied configuration version: %s", err)
+			}
+		}
+	}
+
+	// Detect the parent run if we're running within Terraform Cloud.
+	source := runSourceFromEnv()
+	result.Source = source
+
+	// Build our message data, which is used for the run message and
+	// the apply comment.
+	msgData := &runMessageData{
+		Organization: org,
+		Workspace:    workspace,
+		Attempt:      attempt,
+		Destroy:      destroy,
+		Date:         runMessageDate(),
+		Metadata:     cfg.Metadata,
+		Parent:       source,
+	}
+	if cfg.ConfigurationVersion != nil {
+		msgData.Rollback = cfg.ConfigurationVersion.ID
+	}
+	message, err := renderRunMessage(cfg.Message, msgData)
+	if err != nil {
+		return result, diag.Errorf("Error rendering message: %s", err)
+	}
+
+	// Create a run
+	run, err := client.Runs.Create(ctx, tfe.RunCreateOptions{
+		Message:              tfe.String(message),
+		Workspace:            ws,
+		IsDestroy:            tfe.Bool(destroy),
+		ConfigurationVersion: cfg.ConfigurationVersion,
+
+		// Never auto-apply because we handle all that.
+		AutoApply: tfe.Bool(false),
+	})
+
+	// I THINK if err != nil implies run == nil but we can never be too sure.
+	// If we have a non-nil run, we want to save the ID so we don't dangle
+	// resources.
+	if run != nil {
+		// The ID we use is the run we queue. We can use this to look this
+		// run up again in the case of a partial failure.
+		setId(run.ID)
+		result.RunID = run.ID
+
+		logger = logger.With("run_id", run.ID)
+		ctx = hclog.WithContext(ctx, logger)
+		logger.Info("run created")
+	}
+
+	if err != nil {
+		return result, diag.FromErr(err)
+	}
+
+	// If we reach this point and run is nil, there is a bug in the TFE
+	// API client. But its non-sensical and we can't continue so we must exit.
+	if run == nil {
+		logger.Error("TFE client returned err nil but also run nil")
+		return result, diag.Errorf(
+			"INTERNAL ERROR: run create API did not error, but also did not " +
+				"return a run ID.")
+	}
+
+	// If we hold a lock on the workspace, hand it off to our run now that
+	// it is queued.
+	if diags := handoff.queued(ctx, run); diags.HasError() {
+		return result, diags
+	}
+
+	// Wait for the plan to complete.
+	run, diags = waitForRun(ctx, client, org, run, ws, true, []tfe.RunStatus{
+		tfe.RunPlanned,
+		tfe.RunPlannedAndFinished,
+		tfe.RunErrored,
+		tfe.RunCostEstimated,
+		tfe.RunPolicyChecked,
+		tfe.RunPolicySoftFailed,
+		tfe.RunPolicyOverride,
+	}, []tfe.RunStatus{
+		tfe.RunPending,
+		tfe.RunPlanQueued,
+		tfe.RunPlanning,
+		tfe.RunCostEstimating,
+		tfe.RunPolicyChecking,
+	})
+	if diags != nil {
+		return result, diags
+	}
+
+	// If the run errored, we should have exited already but lets just exit now.
+	if run.Status == tfe.RunErrored || run.Status == tfe.RunPolicySoftFailed {
+		// Clear the ID, we didn't create anything.
+		setId("")
+
+		if policies[runPhasePlan].Enabled {
+			// Retry
+			retryPhase = runPhasePlan
+			goto RETRY
+		}
+
+		return result, diag.Errorf(
+			"Run %q errored during plan. Please open the web UI to view the error",
+			run.ID,
+		)
+	}
+
+	// If the plan has no changes, then we're done.
+	if !run.HasChanges || run.Status == tfe.RunPlannedAndFinished {
+		logger.Info("plan finished, no changes", "status", run.Status)
+		result.StateAfter, diags = recordState()
+		return result, diags
+	}
+
+	// If a policy soft-fails, we need human approval before we continue
+	if run.Status == tfe.RunPolicyOverride {
+		logger.Info("policy check soft-failed, waiting for manual override", "status", run.Status)
+		run, diags = waitForRun(ctx, client, org, run, ws, true, []tfe.RunStatus{
+			tfe.RunConfirmed,
+			tfe.RunApplyQueued,
+			tfe.RunApplying,
+		}, []tfe.RunStatus{run.Status})
+		if diags != nil {
+			return result, diags
+		}
+	}
+
+	// If we're doing a manual confirmation, then we wait for the human to confirm.
+	if !destroy && cfg.ManualConfirm {
+		logger.Info("plan complete, waiting for manual confirm", "status", run.Status)
+		run, diags = waitForRun(ctx, client, org, run, ws, true, []tfe.RunStatus{
+			tfe.RunConfirmed,
+			tfe.RunApplyQueued,
+			tfe.RunApplying,
+		}, []tfe.RunStatus{run.Status})
+		if diags != nil {
+			return result, diags
+		}
+	} else {
+		// Apply the plan.
+		logger.Info("plan complete, confirming apply", "status", run.Status)
+		msgData.Date = runMessageDate()
+		comment, err := renderRunMessage(cfg.ApplyComment, msgData)
+		if err != nil {
+			return result, diag.Errorf("Error rendering apply_comment: %s", err)
+		}
+		if err := client.Runs.Apply(ctx, run.ID, tfe.RunApplyOptions{
+			Comment: tfe.String(comment),
+		}); err != nil {
+			return result, diag.FromErr(err)
+		}
+	}
+
+	// Wait now for the apply to complete
+	run, diags = waitForRun(ctx, client, org, run, ws, false, []tfe.RunStatus{
+		tfe.RunApplied,
+		tfe.RunErrored,
+	}, []tfe.RunStatus{
+		tfe.RunConfirmed,
+		tfe.RunApplyQueued,
+		tfe.RunApplying,
+	})
+	if diags != nil {
+		return result, diags
+	}
+
+	// If the run errored, we should have exited already but lets just exit now.
+	if run.Status == tfe.RunErrored {
+		// Clear the ID, we didn't create anything.
+		setId("")
+
+		if policies[runPhaseApply].Enabled {
+			// Retry
+			retryPhase = runPhaseApply
+			goto RETRY
+		}
+
+		return result, rollbackRun(ctx, client, cfg, rollbackCV, handoff, diag.Errorf(
+			"Run %q errored during apply. Please open the web UI to view the error",
+			run.ID,
+		))
+	}
+
+	// If this is not applied, we're in some unexpected state.
+	if run.Status != tfe.RunApplied {
+		setId("")
+
+		return result, diag.Errorf(
+			"Run %q entered unexpected state %q, expected applied",
+			run.ID, run.Status,
+		)
+	}
+
+	// Record the state that our run produced.
+	result.StateAfter, diags = recordState()
+	return result, diags
+}
+
+// runSourceRunTrigger is the source of runs queued by run triggers.
+const runSourceRunTrigger tfe.RunSource = "tfe-run-trigger"
+
+const (
+	// triggeredRunTimeout is how long discardTriggeredRuns waits for a run
+	// trigger to queue a run. Run triggers queue runs asynchronously after
+	// the source workspace applies, so the run may not exist yet.
+	triggeredRunTimeout = 30 * time.Second
+
+	// triggeredRunPollInterval is how often discardTriggeredRuns checks
+	// for the triggered run.
+	triggeredRunPollInterval = 2 * time.Second
+)
+
+// discardTriggeredRuns discards or cancels the runs in a workspace that
+// were queued by run triggers and haven't been applied. If after isn't
+// zero, this first waits up to triggeredRunTimeout for a triggered run
+// created after it to appear.
+func discardTriggeredRuns(
+	ctx context.Context,
+	client *tfe.Client,
+	workspaceID string,
+	after time.Time,
+) error {
+	logger := hclog.FromContext(ctx)
+	deadline := time.Now().Add(triggeredRunTimeout)
+	for {
+		// We only look at the most recent page since triggered runs that
+		// block us would be at the front of the queue.
+		rl, err := client.Runs.List(ctx, workspaceID, tfe.RunListOptions{})
+		if err != nil {
+			return err
+		}
+
+		seen := after.IsZero()
+		for _, r := range rl.Items {
+			if r.Source != runSourceRunTrigger {
+				continue
+			}
+			if r.CreatedAt.After(after) {
+				seen = true
+			}
+
+			if r.Actions == nil || !(r.Actions.IsDiscardable || r.Actions.IsCancelable) {
+				continue
+			}
+
+			logger.Info("discarding triggered run", "triggered_run_id", r.ID)
+			if err := discardRun(ctx, client, r.ID); err != nil {
+				return err
+			}
+		}
+
+		if seen {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			logger.Warn("no triggered run was queued, continuing")
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(triggeredRunPollInterval):
+		}
+	}
+}
+
+// discardRun discards the run if it is waiting for confirmation, or
+// cancels it if it is still in progress.
+func discardRun(ctx context.Context, client *tfe.Client, id string) error {
+	r, err := client.Runs.Read(ctx, id)
+	if err != nil {
+		return err
+	}
+	if r.Actions == nil {
+		return nil
+	}
+
+	comment := tfe.String("Discarded by terraform-provider-multispace")
+	switch {
+	case r.Actions.IsDiscardable:
+		return client.Runs.Discard(ctx, id, tfe.RunDiscardOptions{Comment: comment})
+	case r.Actions.IsCancelable:
+		return client.Runs.Cancel(ctx, id, tfe.RunCancelOptions{Comment: comment})
+	}
+
+	return nil
+}
+
+// doRunConcurrent executes doRun for each workspace using the base
+// configuration, running at most parallelism at once. With failFast, no
+// new runs are started after the first failure but runs already in
+// progress are allowed to finish. It returns the ID of the run queued for
+// each workspace that completed successfully.
+func doRunConcurrent(
+	ctx context.Context,
+	client *tfe.Client,
+	base runConfig,
+	workspaces []string,
+	parallelism int,
+	failFast bool,
+) (map[string]interface{}, diag.Diagnostics) {
+	logger := logger.Named("run").With(
+		"organization", base.Organization,
+		"destroy", base.Destroy,
+	)
+
+	var (
+		wg     sync.WaitGroup
+		lock   sync.Mutex
+		failed bool
+		diags  diag.Diagnostics
+		runs   = map[string]interface{}{}
+	)
+
+	sem := make(chan struct{}, parallelism)
+	for _, workspace := range workspaces {
+		// Wait for a free slot, then check if we should still start.
+		sem <- struct{}{}
+		lock.Lock()
+		stop := failed && failFast
+		lock.Unlock()
+		if stop {
+			<-sem
+			logger.Info("skipping workspace due to an earlier failure", "workspace", workspace)
+			continue
+		}
+
+		wg.Add(1)
+		go func(workspace string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			cfg := base
+			cfg.Workspace = workspace
+			result, runDiags := doRun(ctx, client, &cfg)
+
+			lock.Lock()
+			defer lock.Unlock()
+			for _, runDiag := range runDiags {
+				runDiag.Summary = fmt.Sprintf("%s: %s", workspace, runDiag.Summary)
+				diags = append(diags, runDiag)
+			}
+			if runDiags.HasError() {
+				failed = true
+				return
+			}
+			if result != nil {
+				runs[workspace] = result.RunID
+			}
+		}(workspace)
+	}
+
+	wg.Wait()
+	return runs, diags
+}
@@ -0,0 +1,52 @@
+package provider
+
+import (
+	"os"
+	"strings"
+)
+
+// runSource is the identity of the Terraform Cloud run that is executing
+// this provider, if any. This is used to trace child runs that we queue
+// back to the parent run that created them.
+type runSource struct {
+	RunID        string
+	Organization string
+	Workspace    string
+}
+
+// runSourceFromEnv detects the parent run from the environment variables
+// that Terraform Cloud sets during a remote run. If we're not running in
+// Terraform Cloud, this returns the zero value.
+func runSourceFromEnv() runSource {
+	result := runSource{
+		RunID:     os.Getenv("TFC_RUN_ID"),
+		Workspace: os.Getenv("TFC_WORKSPACE_NAME"),
+	}
+
+	// The slug is "org/workspace" so we use it to find the organization.
+	if slug := os.Getenv("TFC_WORKSPACE_SLUG"); slug != "" {
+		if idx := strings.Index(slug, "/"); idx > 0 {
+			result.Organization = slug[:idx]
+			if result.Workspace == "" {
+				result.Workspace = slug[idx+1:]
+			}
+		}
+	}
+
+	return result
+}
+
+// flatten returns the value to set for the source attribute.
+func (s runSource) flatten() []interface{} {
+	if s.RunID == "" {
+		return nil
+	}
+
+	return []interface{}{
+		map[string]interface{}{
+			"run_id":       s.RunID,
+			"organization": s.Organization,
+			"workspace":    s.Workspace,
+		},
+	}
+}
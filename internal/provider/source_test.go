@@ -0,0 +1,51 @@
+package provider
+
+import (
+	"os"
+	"testing"
+)
+
+func TestRunSourceFromEnv(t *testing.T) {
+	t.Run("not in TFC", func(t *testing.T) {
+		setenv(t, "TFC_RUN_ID", "")
+		setenv(t, "TFC_WORKSPACE_NAME", "")
+		setenv(t, "TFC_WORKSPACE_SLUG", "")
+
+		actual := runSourceFromEnv()
+		if actual != (runSource{}) {
+			t.Fatalf("bad: %#v", actual)
+		}
+		if actual.flatten() != nil {
+			t.Fatal("should flatten to nil")
+		}
+	})
+
+	t.Run("in TFC", func(t *testing.T) {
+		setenv(t, "TFC_RUN_ID", "run-abc")
+		setenv(t, "TFC_WORKSPACE_NAME", "")
+		setenv(t, "TFC_WORKSPACE_SLUG", "my-org/root")
+
+		actual := runSourceFromEnv()
+		expected := runSource{
+			RunID:        "run-abc",
+			Organization: "my-org",
+			Workspace:    "root",
+		}
+		if actual != expected {
+			t.Fatalf("bad: %#v", actual)
+		}
+	})
+}
+
+// setenv sets an environment variable for the duration of the test.
+func setenv(t *testing.T, k, v string) {
+	old, ok := os.LookupEnv(k)
+	os.Setenv(k, v)
+	t.Cleanup(func() {
+		if ok {
+			os.Setenv(k, old)
+		} else {
+			os.Unsetenv(k)
+		}
+	})
+}
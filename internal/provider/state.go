@@ -0,0 +1,89 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+
+	tfe "github.com/hashicorp/go-tfe"
+)
+
+// currentStateVersion returns the current state version for a workspace.
+// If the workspace has no state yet, this returns nil with no error.
+func currentStateVersion(
+	ctx context.Context,
+	client *tfe.Client,
+	workspaceID string,
+) (*tfe.StateVersion, error) {
+	sv, err := client.StateVersions.Current(ctx, workspaceID)
+	if err == tfe.ErrResourceNotFound {
+		return nil, nil
+	}
+
+	return sv, err
+}
+
+// stateFile is the subset of the Terraform state file format that we use.
+type stateFile struct {
+	Resources []stateResource `json:"resources"`
+}
+
+// stateResource is a resource in a Terraform state file.
+type stateResource struct {
+	Module    string            `json:"module"`
+	Mode      string            `json:"mode"`
+	Type      string            `json:"type"`
+	Name      string            `json:"name"`
+	Provider  string            `json:"provider"`
+	Instances []json.RawMessage `json:"instances"`
+}
+
+// readStateFile downloads and parses the state file of a state version.
+func readStateFile(
+	ctx context.Context,
+	client *tfe.Client,
+	sv *tfe.StateVersion,
+) (*stateFile, error) {
+	raw, err := client.StateVersions.Download(ctx, sv.DownloadURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseStateFile(raw)
+}
+
+// parseStateFile parses the JSON of a Terraform state file.
+func parseStateFile(raw []byte) (*stateFile, error) {
+	var result stateFile
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// managedResources returns the managed resources in the state that have
+// at least one instance. Data sources are not included.
+func (s *stateFile) managedResources() []stateResource {
+	var result []stateResource
+	for _, r := range s.Resources {
+		if r.Mode == "managed" && len(r.Instances) > 0 {
+			result = append(result, r)
+		}
+	}
+
+	return result
+}
+
+// address returns the address of the resource, such as
+// module.vpc.aws_vpc.this. Instance keys are not included.
+func (r *stateResource) address() string {
+	addr := r.Type + "." + r.Name
+	if r.Mode == "data" {
+		addr = "data." + addr
+	}
+	if r.Module != "" {
+		addr = r.Module + "." + addr
+	}
+
+	return addr
+}
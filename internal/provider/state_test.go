@@ -0,0 +1,67 @@
+package provider
+
+import (
+	"testing"
+)
+
+func TestParseStateFile(t *testing.T) {
+	raw := []byte(`{
+  "version": 4,
+  "serial": 3,
+  "resources": [
+    {
+      "mode": "data",
+      "type": "aws_ami",
+      "name": "ubuntu",
+      "provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+      "instances": [{"attributes": {}}]
+    },
+    {
+      "module": "module.vpc",
+      "mode": "managed",
+      "type": "aws_vpc",
+      "name": "this",
+      "provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+      "instances": [{"attributes": {}}]
+    },
+    {
+      "mode": "managed",
+      "type": "aws_instance",
+      "name": "web",
+      "provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+      "instances": []
+    }
+  ]
+}`)
+
+	s, err := parseStateFile(raw)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if len(s.Resources) != 3 {
+		t.Fatalf("expected 3 resources, got %d", len(s.Resources))
+	}
+
+	managed := s.managedResources()
+	if len(managed) != 1 {
+		t.Fatalf("expected 1 managed resource, got %d", len(managed))
+	}
+	if r := managed[0]; r.Module != "module.vpc" || r.Type != "aws_vpc" || r.Name != "this" {
+		t.Fatalf("bad resource: %#v", r)
+	}
+	if addr := managed[0].address(); addr != "module.vpc.aws_vpc.this" {
+		t.Fatalf("bad address: %s", addr)
+	}
+	if addr := s.Resources[0].address(); addr != "data.aws_ami.ubuntu" {
+		t.Fatalf("bad address: %s", addr)
+	}
+
+	// An empty state after a destroy has no resources.
+	s, err = parseStateFile([]byte(`{"version": 4, "serial": 4, "resources": []}`))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if len(s.managedResources()) != 0 {
+		t.Fatal("expected no managed resources")
+	}
+}
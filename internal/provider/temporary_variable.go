@@ -0,0 +1,297 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	tfe "github.com/hashicorp/go-tfe"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// temporaryVariable is a workspace variable that is set only for the
+// duration of our run.
+type temporaryVariable struct {
+	Key       string
+	Value     string
+	Category  tfe.CategoryType
+	HCL       bool
+	Sensitive bool
+}
+
+// temporaryVariableSchema is the schema for the temporary_variable blocks.
+func temporaryVariableSchema() *schema.Schema {
+	return &schema.Schema{
+		Description: temporaryVariableDescriptions["temporary_variable"],
+		Type:        schema.TypeList,
+		Optional:    true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"key": {
+					Description: temporaryVariableDescriptions["key"],
+					Type:        schema.TypeString,
+					Required:    true,
+				},
+
+				"value": {
+					Description: temporaryVariableDescriptions["value"],
+					Type:        schema.TypeString,
+					Required:    true,
+					Sensitive:   true,
+				},
+
+				"category": {
+					Description: temporaryVariableDescriptions["category"],
+					Type:        schema.TypeString,
+					Optional:    true,
+					Default:     string(tfe.CategoryTerraform),
+					ValidateFunc: validation.StringInSlice([]string{
+						string(tfe.CategoryTerraform),
+						string(tfe.CategoryEnv),
+					}, false),
+				},
+
+				"hcl": {
+					Description: temporaryVariableDescriptions["hcl"],
+					Type:        schema.TypeBool,
+					Optional:    true,
+					Default:     false,
+				},
+
+				"sensitive": {
+					Description: temporaryVariableDescriptions["sensitive"],
+					Type:        schema.TypeBool,
+					Optional:    true,
+					Default:     false,
+				},
+			},
+		},
+	}
+}
+
+// readTemporaryVariables reads the temporary_variable blocks.
+func readTemporaryVariables(d *schema.ResourceData) []temporaryVariable {
+	var result []temporaryVariable
+	for _, raw := range d.Get("temporary_variable").([]interface{}) {
+		m := raw.(map[string]interface{})
+		result = append(result, temporaryVariable{
+			Key:       m["key"].(string),
+			Value:     m["value"].(string),
+			Category:  tfe.CategoryType(m["category"].(string)),
+			HCL:       m["hcl"].(bool),
+			Sensitive: m["sensitive"].(bool),
+		})
+	}
+
+	return result
+}
+
+// setTemporaryVariables creates or overrides the variables in the
+// workspace. The returned function restores the original variables and
+// must be called once the run is done. If any variable fails to be set,
+// the variables set so far are restored before returning.
+func setTemporaryVariables(
+	ctx context.Context,
+	client *tfe.Client,
+	org string,
+	workspace string,
+	vars []temporaryVariable,
+) (func() diag.Diagnostics, diag.Diagnostics) {
+	if len(vars) == 0 {
+		return nil, nil
+	}
+
+	ws, err := client.Workspaces.Read(ctx, org, workspace)
+	if err != nil {
+		return nil, diag.FromErr(err)
+	}
+	workspaceID := ws.ID
+
+	existing, err := listVariables(ctx, client, workspaceID)
+	if err != nil {
+		return nil, diag.Errorf("Failed to retrieve workspace variables: %s", err)
+	}
+
+	logger := logger.Named("variables").With(
+		"organization", org,
+		"workspace", workspace,
+	)
+
+	// restores are run in reverse order to undo our changes.
+	var restores []func(context.Context) diag.Diagnostics
+	restore := func() diag.Diagnostics {
+		// We use a fresh context because we want to restore the variables
+		// even if the run was canceled or timed out.
+		ctx := context.Background()
+
+		var diags diag.Diagnostics
+		for i := len(restores) - 1; i >= 0; i-- {
+			diags = append(diags, restores[i](ctx)...)
+		}
+
+		return diags
+	}
+
+	for _, v := range vars {
+		v := v
+		var orig *tfe.Variable
+		for _, e := range existing {
+			if e.Key == v.Key && e.Category == v.Category {
+				orig = e
+				break
+			}
+		}
+
+		if orig == nil {
+			logger.Info("creating temporary variable", "key", v.Key, "category", v.Category)
+			created, err := client.Variables.Create(ctx, workspaceID, tfe.VariableCreateOptions{
+				Key:         tfe.String(v.Key),
+				Value:       tfe.String(v.Value),
+				Description: tfe.String("Temporary variable set by terraform-provider-multispace"),
+				Category:    tfe.Category(v.Category),
+				HCL:         tfe.Bool(v.HCL),
+				Sensitive:   tfe.Bool(v.Sensitive),
+			})
+			if err != nil {
+				return nil, append(restore(), diag.Errorf(
+					"Failed to create temporary variable %q: %s", v.Key, err)...)
+			}
+
+			restores = append(restores, func(ctx context.Context) diag.Diagnostics {
+				logger.Info("deleting temporary variable", "key", v.Key, "category", v.Category)
+				if err := client.Variables.Delete(ctx, workspaceID, created.ID); err != nil {
+					return restoreVariableFailed(fmt.Errorf("deleting %q: %s", v.Key, err))
+				}
+
+				return nil
+			})
+			continue
+		}
+
+		// We can't read the value of a sensitive variable so we would have
+		// no way to restore it. This is a limitation of Terraform Cloud so
+		// the only option is to refuse before changing anything else.
+		if orig.Sensitive {
+			return nil, append(restore(), diag.Errorf(
+				"Workspace variable %q is sensitive and can't be overridden "+
+					"temporarily because Terraform Cloud never returns the value "+
+					"of a sensitive variable, so it couldn't be restored. Remove "+
+					"the temporary_variable block or make the workspace variable "+
+					"non-sensitive.", v.Key)...)
+		}
+
+		logger.Info("overriding variable", "key", v.Key, "category", v.Category)
+		if _, err := client.Variables.Update(ctx, workspaceID, orig.ID, tfe.VariableUpdateOptions{
+			Value:     tfe.String(v.Value),
+			HCL:       tfe.Bool(v.HCL),
+			Sensitive: tfe.Bool(v.Sensitive),
+		}); err != nil {
+			return nil, append(restore(), diag.Errorf(
+				"Failed to override variable %q: %s", v.Key, err)...)
+		}
+
+		restores = append(restores, func(ctx context.Context) diag.Diagnostics {
+			logger.Info("restoring variable", "key", v.Key, "category", v.Category)
+			return restoreVariable(ctx, client, workspaceID, orig, v.Sensitive)
+		})
+	}
+
+	return restore, nil
+}
+
+// restoreVariable restores a variable we overrode to its original value.
+// A variable can't be made non-sensitive again and an update can't set
+// an empty value, so in those cases the variable is recreated. Keys are
+// unique so our variable is renamed out of the way first and only deleted
+// once the original exists again. The recreated variable has a new ID,
+// which is reported as a warning.
+func restoreVariable(
+	ctx context.Context,
+	client *tfe.Client,
+	workspaceID string,
+	orig *tfe.Variable,
+	madeSensitive bool,
+) diag.Diagnostics {
+	if !madeSensitive && orig.Value != "" {
+		_, err := client.Variables.Update(ctx, workspaceID, orig.ID, tfe.VariableUpdateOptions{
+			Value: tfe.String(orig.Value),
+			HCL:   tfe.Bool(orig.HCL),
+		})
+		if err != nil {
+			return restoreVariableFailed(fmt.Errorf("restoring %q: %s", orig.Key, err))
+		}
+
+		return nil
+	}
+
+	tempKey := orig.Key + "_multispace_restore"
+	if _, err := client.Variables.Update(ctx, workspaceID, orig.ID, tfe.VariableUpdateOptions{
+		Key: tfe.String(tempKey),
+	}); err != nil {
+		return restoreVariableFailed(fmt.Errorf("restoring %q: %s", orig.Key, err))
+	}
+
+	created, err := client.Variables.Create(ctx, workspaceID, tfe.VariableCreateOptions{
+		Key:         tfe.String(orig.Key),
+		Value:       tfe.String(orig.Value),
+		Description: tfe.String(orig.Description),
+		Category:    tfe.Category(orig.Category),
+		HCL:         tfe.Bool(orig.HCL),
+		Sensitive:   tfe.Bool(false),
+	})
+	if err != nil {
+		// Put the key back so the workspace at least keeps the variable,
+		// even though it still has our value.
+		if _, renameErr := client.Variables.Update(ctx, workspaceID, orig.ID, tfe.VariableUpdateOptions{
+			Key: tfe.String(orig.Key),
+		}); renameErr != nil {
+			return restoreVariableFailed(fmt.Errorf(
+				"restoring %q: %s; the variable was left renamed to %q: %s",
+				orig.Key, err, tempKey, renameErr))
+		}
+
+		return restoreVariableFailed(fmt.Errorf(
+			"restoring %q: %s; the variable still has the temporary value",
+			orig.Key, err))
+	}
+
+	diags := diag.Diagnostics{{
+		Severity: diag.Warning,
+		Summary:  "Workspace variable recreated",
+		Detail: fmt.Sprintf(
+			"Variable %q had to be recreated to restore it, so its ID changed "+
+				"from %q to %q. Anything that refers to the variable by ID "+
+				"must be updated.", orig.Key, orig.ID, created.ID),
+	}}
+	if err := client.Variables.Delete(ctx, workspaceID, orig.ID); err != nil {
+		return append(diags, restoreVariableFailed(fmt.Errorf(
+			"deleting %q after restoring %q: %s", tempKey, orig.Key, err))...)
+	}
+
+	return diags
+}
+
+// restoreVariableFailed returns the diagnostics for a variable that
+// couldn't be restored.
+func restoreVariableFailed(err error) diag.Diagnostics {
+	return diag.Diagnostics{{
+		Severity: diag.Error,
+		Summary:  "Failed to restore workspace variable",
+		Detail:   err.Error(),
+	}}
+}
+
+var temporaryVariableDescriptions = map[string]string{
+	"temporary_variable": "Workspace variables to set only while our run is in " +
+		"progress. Existing variables with the same key and category are " +
+		"overridden and the original values are restored once the run is " +
+		"done, even if it fails. Variables that didn't exist are deleted. " +
+		"Existing sensitive variables can't be overridden since their values " +
+		"can't be read back to restore them.",
+	"key":       "The name of the variable.",
+	"value":     "The value of the variable.",
+	"category":  "Whether this is a `terraform` or `env` variable.",
+	"hcl":       "Whether to evaluate the value of the variable as HCL.",
+	"sensitive": "Whether the variable is sensitive while it is set.",
+}
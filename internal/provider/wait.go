@@ -5,11 +5,10 @@ package provider
 
 import (
 	"context"
-	"fmt"
-	"log"
 	"math"
 	"time"
 
+	"github.com/hashicorp/go-hclog"
 	tfe "github.com/hashicorp/go-tfe"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 )
@@ -44,6 +43,7 @@ func waitForRun(
 		progress = []tfe.RunStatus{tfe.RunPending, tfe.RunConfirmed}
 	}
 
+	logger := hclog.FromContext(ctx)
 	started := time.Now()
 	updated := started
 	for i := 0; ; i++ {
@@ -63,7 +63,7 @@ func waitForRun(
 		// If we have terminal states and we reached any, we're done.
 		for _, s := range terminal {
 			if r.Status == s {
-				log.Printf("[DEBUG] reached terminal state %q", r.Status)
+				logger.Debug("reached terminal state", "status", r.Status)
 				return r, nil
 			}
 		}
@@ -78,7 +78,7 @@ func waitForRun(
 			}
 		}
 		if !found {
-			log.Printf("[DEBUG] non-progressive state, exiting %q", r.Status)
+			logger.Debug("non-progressive state, exiting", "status", r.Status)
 			return r, nil
 		}
 
@@ -87,13 +87,10 @@ func waitForRun(
 		if i == 0 || current.Sub(updated).Seconds() > 30 {
 			updated = current
 			position := 0
-			elapsed := ""
-
-			// Calculate and set the elapsed time.
-			if i > 0 {
-				elapsed = fmt.Sprintf(
-					" (%s elapsed)", current.Sub(started).Truncate(30*time.Second))
-			}
+			logger := logger.With(
+				"status", r.Status,
+				"elapsed", current.Sub(started).Truncate(30*time.Second).String(),
+			)
 
 			// Retrieve the workspace used to run this operation in.
 			w, err = client.Workspaces.ReadByID(ctx, w.ID)
@@ -109,9 +106,7 @@ func waitForRun(
 					return r, diag.Errorf("Failed to retrieve current run: %s", err)
 				}
 				if cr.Status == tfe.RunPending {
-					log.Printf(
-						"[DEBUG] Waiting for the manually locked workspace to " +
-							"be unlocked..." + elapsed)
+					logger.Debug("waiting for the manually locked workspace to be unlocked")
 					continue
 				}
 			}
@@ -165,10 +160,9 @@ func waitForRun(
 				}
 
 				if position > 0 {
-					log.Printf(
-						"[INFO] Waiting for %d run(s) to finish before being queued...%s",
-						position,
-						elapsed,
+					logger.Info(
+						"waiting for runs to finish before being queued",
+						"position", position,
 					)
 					continue
 				}
@@ -204,15 +198,14 @@ func waitForRun(
 				if err != nil {
 					return r, diag.Errorf("Failed to retrieve capacity: %s", err)
 				}
-				log.Printf(
-					"[INFO] Waiting for %d queued run(s) to finish before starting...%s",
-					position-c.Running,
-					elapsed,
+				logger.Info(
+					"waiting for queued runs to finish before starting",
+					"position", position-c.Running,
 				)
 				continue
 			}
 
-			log.Printf("[DEBUG] Waiting for the run to start...%s", elapsed)
+			logger.Debug("waiting for the run to start")
 		}
 	}
 }
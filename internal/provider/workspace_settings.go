@@ -0,0 +1,162 @@
+package provider
+
+import (
+	"context"
+
+	tfe "github.com/hashicorp/go-tfe"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// workspaceSettings are workspace settings that are changed only for the
+// duration of our run. Empty fields are left unchanged.
+type workspaceSettings struct {
+	ExecutionMode    string
+	AgentPoolID      string
+	TerraformVersion string
+}
+
+// workspaceSettingsSchema is the schema for the workspace_settings block.
+func workspaceSettingsSchema() *schema.Schema {
+	return &schema.Schema{
+		Description: workspaceSettingsDescriptions["workspace_settings"],
+		Type:        schema.TypeList,
+		Optional:    true,
+		MaxItems:    1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"execution_mode": {
+					Description: workspaceSettingsDescriptions["execution_mode"],
+					Type:        schema.TypeString,
+					Optional:    true,
+					ValidateFunc: validation.StringInSlice([]string{
+						"remote",
+						"agent",
+					}, false),
+				},
+
+				"agent_pool_id": {
+					Description: workspaceSettingsDescriptions["agent_pool_id"],
+					Type:        schema.TypeString,
+					Optional:    true,
+				},
+
+				"terraform_version": {
+					Description: workspaceSettingsDescriptions["terraform_version"],
+					Type:        schema.TypeString,
+					Optional:    true,
+				},
+			},
+		},
+	}
+}
+
+// readWorkspaceSettings reads the workspace_settings block, returning nil
+// if it isn't set.
+func readWorkspaceSettings(d *schema.ResourceData) *workspaceSettings {
+	if _, ok := d.GetOk("workspace_settings"); !ok {
+		return nil
+	}
+
+	return &workspaceSettings{
+		ExecutionMode:    d.Get("workspace_settings.0.execution_mode").(string),
+		AgentPoolID:      d.Get("workspace_settings.0.agent_pool_id").(string),
+		TerraformVersion: d.Get("workspace_settings.0.terraform_version").(string),
+	}
+}
+
+// updateOptions returns the options to apply the settings to the workspace
+// and the options to restore the workspace afterwards. If the workspace
+// already has all the settings, ok is false.
+func (s *workspaceSettings) updateOptions(ws *tfe.Workspace) (apply, restore tfe.WorkspaceUpdateOptions, ok bool) {
+	if s.ExecutionMode != "" && s.ExecutionMode != ws.ExecutionMode {
+		apply.ExecutionMode = tfe.String(s.ExecutionMode)
+		restore.ExecutionMode = tfe.String(ws.ExecutionMode)
+		ok = true
+
+		// Agent mode requires an agent pool, so restoring it requires the
+		// original pool as well.
+		if ws.ExecutionMode == "agent" {
+			restore.AgentPoolID = tfe.String(ws.AgentPoolID)
+		}
+	}
+
+	if s.AgentPoolID != "" && s.AgentPoolID != ws.AgentPoolID {
+		apply.AgentPoolID = tfe.String(s.AgentPoolID)
+		ok = true
+
+		// If the workspace had no agent pool, it can't have been in agent
+		// mode and changing the execution mode back clears the pool.
+		if ws.AgentPoolID != "" {
+			restore.AgentPoolID = tfe.String(ws.AgentPoolID)
+		}
+	}
+
+	if s.TerraformVersion != "" && s.TerraformVersion != ws.TerraformVersion {
+		apply.TerraformVersion = tfe.String(s.TerraformVersion)
+		restore.TerraformVersion = tfe.String(ws.TerraformVersion)
+		ok = true
+	}
+
+	return apply, restore, ok
+}
+
+// setWorkspaceSettings changes the workspace settings. The returned
+// function restores the original settings and must be called once the
+// run is done. If nothing needs to change, the returned function is nil.
+func setWorkspaceSettings(
+	ctx context.Context,
+	client *tfe.Client,
+	org string,
+	workspace string,
+	s *workspaceSettings,
+) (func() diag.Diagnostics, diag.Diagnostics) {
+	if s == nil {
+		return nil, nil
+	}
+
+	ws, err := client.Workspaces.Read(ctx, org, workspace)
+	if err != nil {
+		return nil, diag.FromErr(err)
+	}
+
+	apply, restore, ok := s.updateOptions(ws)
+	if !ok {
+		return nil, nil
+	}
+
+	logger := logger.Named("settings").With(
+		"organization", org,
+		"workspace", workspace,
+	)
+
+	logger.Info("changing workspace settings for our run")
+	if _, err := client.Workspaces.UpdateByID(ctx, ws.ID, apply); err != nil {
+		return nil, diag.Errorf("Failed to change settings of workspace %q: %s", workspace, err)
+	}
+
+	return func() diag.Diagnostics {
+		// We use a fresh context because we want to restore the settings
+		// even if the run was canceled or timed out.
+		logger.Info("restoring workspace settings")
+		if _, err := client.Workspaces.UpdateByID(context.Background(), ws.ID, restore); err != nil {
+			return diag.Errorf(
+				"Failed to restore settings of workspace %q: %s", workspace, err)
+		}
+
+		return nil
+	}, nil
+}
+
+var workspaceSettingsDescriptions = map[string]string{
+	"workspace_settings": "Workspace settings to change only while our run is " +
+		"in progress. The original settings are restored once the run is " +
+		"done, even if it fails. Settings that aren't set are left unchanged. " +
+		"Auto-apply can't be changed since our runs are always confirmed " +
+		"by the provider and never auto-apply.",
+	"execution_mode": "The execution mode to use, either `remote` or `agent`.",
+	"agent_pool_id": "The ID of the agent pool to use. This requires the " +
+		"`agent` execution mode.",
+	"terraform_version": "The Terraform version to use.",
+}
@@ -0,0 +1,68 @@
+package provider
+
+import (
+	"testing"
+
+	tfe "github.com/hashicorp/go-tfe"
+)
+
+func TestWorkspaceSettingsUpdateOptions(t *testing.T) {
+	ws := &tfe.Workspace{
+		ExecutionMode:    "remote",
+		TerraformVersion: "1.0.11",
+	}
+
+	// Nothing to change
+	s := &workspaceSettings{ExecutionMode: "remote", TerraformVersion: "1.0.11"}
+	if _, _, ok := s.updateOptions(ws); ok {
+		t.Fatal("expected no changes")
+	}
+
+	// Switch to an agent pool and pin a version
+	s = &workspaceSettings{
+		ExecutionMode:    "agent",
+		AgentPoolID:      "apool-123",
+		TerraformVersion: "0.14.11",
+	}
+	apply, restore, ok := s.updateOptions(ws)
+	if !ok {
+		t.Fatal("expected changes")
+	}
+	if *apply.ExecutionMode != "agent" || *apply.AgentPoolID != "apool-123" || *apply.TerraformVersion != "0.14.11" {
+		t.Fatalf("bad apply: %#v", apply)
+	}
+	if *restore.ExecutionMode != "remote" || *restore.TerraformVersion != "1.0.11" {
+		t.Fatalf("bad restore: %#v", restore)
+	}
+	if restore.AgentPoolID != nil {
+		t.Fatalf("agent pool should not be restored: %#v", restore)
+	}
+
+	// Switch agent pools
+	ws = &tfe.Workspace{ExecutionMode: "agent", AgentPoolID: "apool-old"}
+	s = &workspaceSettings{AgentPoolID: "apool-new"}
+	apply, restore, ok = s.updateOptions(ws)
+	if !ok {
+		t.Fatal("expected changes")
+	}
+	if apply.ExecutionMode != nil || *apply.AgentPoolID != "apool-new" {
+		t.Fatalf("bad apply: %#v", apply)
+	}
+	if *restore.AgentPoolID != "apool-old" {
+		t.Fatalf("bad restore: %#v", restore)
+	}
+
+	// Switch from an agent pool to remote execution
+	ws = &tfe.Workspace{ExecutionMode: "agent", AgentPoolID: "apool-old"}
+	s = &workspaceSettings{ExecutionMode: "remote"}
+	apply, restore, ok = s.updateOptions(ws)
+	if !ok {
+		t.Fatal("expected changes")
+	}
+	if *apply.ExecutionMode != "remote" || apply.AgentPoolID != nil {
+		t.Fatalf("bad apply: %#v", apply)
+	}
+	if *restore.ExecutionMode != "agent" || restore.AgentPoolID == nil || *restore.AgentPoolID != "apool-old" {
+		t.Fatalf("bad restore: %#v", restore)
+	}
+}
@@ -0,0 +1,136 @@
+package provider
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	tfe "github.com/hashicorp/go-tfe"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// workspaceSelectorKeys are the keys used to select workspaces by name,
+// tag, or prefix in data sources that read many workspaces.
+var workspaceSelectorKeys = []string{"workspaces", "tags", "prefix"}
+
+// addWorkspaceSelectorSchema adds the workspace selector fields to the
+// schema. Workspaces matching any of the set fields are selected.
+func addWorkspaceSelectorSchema(s map[string]*schema.Schema) {
+	s["workspaces"] = &schema.Schema{
+		Description:  workspaceSelectorDescriptions["workspaces"],
+		Type:         schema.TypeList,
+		Optional:     true,
+		Elem:         &schema.Schema{Type: schema.TypeString},
+		AtLeastOneOf: workspaceSelectorKeys,
+	}
+
+	s["tags"] = &schema.Schema{
+		Description:  workspaceSelectorDescriptions["tags"],
+		Type:         schema.TypeList,
+		Optional:     true,
+		Elem:         &schema.Schema{Type: schema.TypeString},
+		AtLeastOneOf: workspaceSelectorKeys,
+	}
+
+	s["prefix"] = &schema.Schema{
+		Description:  workspaceSelectorDescriptions["prefix"],
+		Type:         schema.TypeString,
+		Optional:     true,
+		AtLeastOneOf: workspaceSelectorKeys,
+	}
+}
+
+// selectWorkspaces returns the workspaces selected by the selector fields
+// of the schema, sorted by name.
+func selectWorkspaces(
+	ctx context.Context,
+	client *tfe.Client,
+	org string,
+	d *schema.ResourceData,
+) ([]*tfe.Workspace, error) {
+	selected := map[string]*tfe.Workspace{}
+
+	for _, raw := range d.Get("workspaces").([]interface{}) {
+		ws, err := client.Workspaces.Read(ctx, org, raw.(string))
+		if err != nil {
+			return nil, err
+		}
+		selected[ws.Name] = ws
+	}
+
+	var tags []string
+	for _, raw := range d.Get("tags").([]interface{}) {
+		tags = append(tags, raw.(string))
+	}
+	if len(tags) > 0 {
+		list, err := listWorkspaces(ctx, client, org, tfe.WorkspaceListOptions{
+			Tags: tfe.String(strings.Join(tags, ",")),
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, ws := range list {
+			selected[ws.Name] = ws
+		}
+	}
+
+	if prefix := d.Get("prefix").(string); prefix != "" {
+		// The API only supports searching for a partial name so we have
+		// to filter the results ourselves.
+		list, err := listWorkspaces(ctx, client, org, tfe.WorkspaceListOptions{
+			Search: tfe.String(prefix),
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, ws := range list {
+			if strings.HasPrefix(ws.Name, prefix) {
+				selected[ws.Name] = ws
+			}
+		}
+	}
+
+	result := make([]*tfe.Workspace, 0, len(selected))
+	for _, ws := range selected {
+		result = append(result, ws)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+
+	return result, nil
+}
+
+// listWorkspaces returns all the workspaces in the organization matching
+// the options, reading every page.
+func listWorkspaces(
+	ctx context.Context,
+	client *tfe.Client,
+	org string,
+	options tfe.WorkspaceListOptions,
+) ([]*tfe.Workspace, error) {
+	var result []*tfe.Workspace
+	for {
+		wl, err := client.Workspaces.List(ctx, org, options)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, wl.Items...)
+
+		// Exit the loop when we've seen all pages.
+		if wl.Pagination == nil || wl.CurrentPage >= wl.TotalPages {
+			break
+		}
+
+		// Update the page number to get the next page.
+		options.PageNumber = wl.NextPage
+	}
+
+	return result, nil
+}
+
+var workspaceSelectorDescriptions = map[string]string{
+	"workspaces": "The names of workspaces to select.",
+	"tags":       "Select the workspaces that have all of these tags.",
+	"prefix":     "Select the workspaces whose names start with this prefix.",
+}